@@ -5,9 +5,11 @@ import (
 	"goapi-starter/internal/cache"
 	"goapi-starter/internal/config"
 	"goapi-starter/internal/database"
+	"goapi-starter/internal/jobs"
 	"goapi-starter/internal/logger"
-	"goapi-starter/internal/models"
 	"goapi-starter/internal/routes"
+	"goapi-starter/internal/services"
+	"goapi-starter/internal/storage"
 	"net/http"
 	"os"
 	"os/signal"
@@ -28,17 +30,46 @@ func main() {
 	logger.Info().Msg("Initializing database connection")
 	database.InitDB()
 
-	// Initialize Redis
-	logger.Info().Msg("Initializing Redis connection")
-	cache.InitRedis()
+	// Initialize the cache backend
+	logger.Info().Msg("Initializing cache")
+	cache.InitStore()
+
+	// Initialize blob storage
+	logger.Info().Msg("Initializing blob storage")
+	storage.InitStorage()
 
 	// Auto migrate the schema
 	logger.Info().Msg("Running database migrations")
-	if err := database.DB.AutoMigrate(&models.User{}, &models.DummyProduct{}, &models.RefreshToken{}); err != nil {
+	if err := database.RunMigrations(); err != nil {
 		logger.Fatal().Err(err).Msg("Failed to run database migrations")
 	}
 	logger.Info().Msg("Database migrations completed successfully")
 
+	// Start background jobs
+	logger.Info().Msg("Starting background jobs")
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	retention := config.AppConfig.Products.TrashRetention
+	jobs.Every(jobsCtx, "purge_deleted_dummy_products", config.AppConfig.Products.TrashPurgeInterval, func(ctx context.Context) error {
+		_, err := services.PurgeDeletedDummyProducts(ctx, retention)
+		return err
+	})
+
+	// Exports are written to each instance's own disk, so every instance cleans up its own
+	exportDir := config.AppConfig.Products.ExportDir
+	jobs.EveryLocal(jobsCtx, "purge_expired_exports", time.Hour, func(ctx context.Context) error {
+		_, err := services.PurgeExpiredExports(ctx, exportDir, jobs.JobTTL)
+		return err
+	})
+
+	jobs.Every(jobsCtx, "release_expired_reservations", time.Minute, func(ctx context.Context) error {
+		if _, err := services.ReleaseExpiredReservations(ctx); err != nil {
+			return err
+		}
+		return services.UpdateLowStockGauge(ctx)
+	})
+
 	// Setup router
 	logger.Info().Msg("Setting up HTTP routes")
 	router := routes.SetupRouter()
@@ -70,6 +101,9 @@ func main() {
 
 	logger.Info().Msg("Shutting down server...")
 
+	// Stop background jobs before the server drains
+	stopJobs()
+
 	// Create a deadline for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"errors"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrEmptyPrefix is returned when asked to purge without a prefix
+	ErrEmptyPrefix = errors.New("prefix must not be empty")
+	// ErrProtectedPrefix is returned when a purge would reach protected keys
+	ErrProtectedPrefix = errors.New("prefix is protected")
+)
+
+// RateLimitPrefix prefixes the rate limiters' request counters and blocks
+const RateLimitPrefix = "ratelimit:"
+
+// protectedPrefixes are never purged in bulk: dropping the blacklist would
+// make revoked tokens valid again, dropping locks would reset their fencing
+// counters and let two holders in at once, dropping rate limits would lift
+// every block, including those on clients guessing passwords, and dropping tag
+// sets would leave their members out of reach of tag invalidation
+var protectedPrefixes = []string{TokenBlacklistPrefix + ":", DistributedLockPrefix, RateLimitPrefix, TagPrefix}
+
+// PrefixStats summarizes the keys sharing their first segment, such as "user"
+type PrefixStats struct {
+	Prefix string `json:"prefix"`
+	Keys   int64  `json:"keys"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// KeyInfo describes a single cache key
+type KeyInfo struct {
+	Key        string `json:"key"`
+	TTLSeconds int64  `json:"ttl_seconds"` // -1 for keys that never expire
+	Bytes      int64  `json:"bytes"`
+}
+
+// KeyPrefixes counts the keys in the namespace and their memory use by prefix.
+// It scans every key, so it is meant for occasional administrative use.
+func KeyPrefixes() ([]PrefixStats, error) {
+	metrics.RecordCacheOperation("scan", "prefixes")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("scan", time.Since(startTime))
+	}()
+
+	stats := make(map[string]*PrefixStats)
+	err := Backend.Scan(ctx, "", func(keys []string) error {
+		sizes, err := Backend.Size(ctx, keys...)
+		if err != nil {
+			return err
+		}
+		for i, key := range keys {
+			prefix, _, _ := strings.Cut(key, ":")
+			s, ok := stats[prefix]
+			if !ok {
+				s = &PrefixStats{Prefix: prefix}
+				stats[prefix] = s
+			}
+			s.Keys++
+			s.Bytes += sizes[i]
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to scan cache keys")
+		return nil, err
+	}
+
+	result := make([]PrefixStats, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Prefix < result[j].Prefix })
+	return result, nil
+}
+
+// InspectKey returns the TTL and size of a key; found is false when it does not exist
+func InspectKey(key string) (KeyInfo, bool, error) {
+	metrics.RecordCacheOperation("inspect", "default")
+
+	ttl, err := Backend.TTL(ctx, key)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to get TTL from cache")
+		return KeyInfo{}, false, err
+	}
+	if ttl == TTLMissing {
+		return KeyInfo{}, false, nil
+	}
+
+	sizes, err := Backend.Size(ctx, key)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to get cache key size")
+		return KeyInfo{}, false, err
+	}
+
+	info := KeyInfo{Key: key, TTLSeconds: -1, Bytes: sizes[0]}
+	if ttl != TTLNoExpiry {
+		info.TTLSeconds = int64(ttl.Round(time.Second) / time.Second)
+	}
+	return info, true, nil
+}
+
+// FlushPrefix deletes every key in the namespace starting with prefix, a
+// batch at a time, and returns how many were deleted. Unlike a FLUSHALL it
+// leaves other namespaces and other prefixes alone.
+func FlushPrefix(prefix string) (int64, error) {
+	if prefix == "" {
+		return 0, ErrEmptyPrefix
+	}
+	for _, protected := range protectedPrefixes {
+		if strings.HasPrefix(protected, prefix) || strings.HasPrefix(prefix, protected) {
+			return 0, ErrProtectedPrefix
+		}
+	}
+
+	metrics.RecordCacheOperation("flush", "prefix")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("flush", time.Since(startTime))
+	}()
+
+	var deleted int64
+	err := Backend.Scan(ctx, prefix, func(keys []string) error {
+		if err := Backend.Delete(ctx, keys...); err != nil {
+			return err
+		}
+		invalidate(keys...)
+		deleted += int64(len(keys))
+		return nil
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("prefix", prefix).Int64("deleted", deleted).Msg("Failed to flush cache prefix")
+		return deleted, err
+	}
+
+	logger.Info().Str("prefix", prefix).Int64("deleted", deleted).Msg("Successfully flushed cache prefix")
+	return deleted, nil
+}
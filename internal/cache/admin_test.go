@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNamespacedAdministration(t *testing.T) {
+	previous := Backend
+	shared := NewMemoryStore()
+	Backend = WithNamespace(shared, "goapi:test")
+	defer func() { Backend = previous }()
+
+	other := WithNamespace(shared, "goapi:other")
+	other.Set(context.Background(), "user:1", []byte("{}"), 0)
+
+	SetWithTTL("user:1", 1, time.Minute)
+	SetWithTTL("user:2", 2, time.Minute)
+	BlacklistToken("access", "0123456789abcdef", time.Minute)
+
+	prefixes, err := KeyPrefixes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prefixes) != 2 || prefixes[0].Prefix != "blacklist" || prefixes[1].Prefix != "user" || prefixes[1].Keys != 2 {
+		t.Fatalf("KeyPrefixes = %+v", prefixes)
+	}
+	if prefixes[1].Bytes <= 0 {
+		t.Error("expected the size of the user keys to be reported")
+	}
+
+	info, found, err := InspectKey("user:1")
+	if err != nil || !found || info.TTLSeconds != 60 || info.Bytes <= 0 {
+		t.Errorf("InspectKey = %+v, %v, %v", info, found, err)
+	}
+
+	for _, prefix := range []string{"blacklist:", "blacklist", "ratelimit:user:", "dlock:", "tag:", "tag:products"} {
+		if _, err := FlushPrefix(prefix); err != ErrProtectedPrefix {
+			t.Errorf("FlushPrefix(%s) = %v, want ErrProtectedPrefix", prefix, err)
+		}
+	}
+	if _, err := FlushPrefix(""); err != ErrEmptyPrefix {
+		t.Errorf("FlushPrefix(\"\") = %v, want ErrEmptyPrefix", err)
+	}
+	if deleted, err := FlushPrefix("user:"); err != nil || deleted != 2 {
+		t.Errorf("FlushPrefix(user:) = %d, %v", deleted, err)
+	}
+
+	if _, found, _ := InspectKey("user:1"); found {
+		t.Error("expected user keys to be flushed")
+	}
+	if blacklisted, _ := IsAccessTokenBlacklisted("0123456789abcdef"); !blacklisted {
+		t.Error("expected the blacklist to survive")
+	}
+	if _, found, _ := other.Get(context.Background(), "user:1"); !found {
+		t.Error("expected other namespaces to be untouched")
+	}
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"sync"
+	"time"
+)
+
+// blacklistFalsePositiveRate bounds how often a token that was never revoked
+// is rejected by the local blacklist while the cache is down
+const blacklistFalsePositiveRate = 0.001
+
+// blacklistFilter is nil for backends that cannot fail
+var blacklistFilter *localBlacklist
+
+// localBlacklist mirrors the token blacklist in a bloom filter, so revoked
+// tokens stay rejected while the cache is unreachable. The filter is rebuilt
+// from the cache every CACHE_BLACKLIST_SYNC_INTERVAL_SECONDS, which drops
+// expired tokens; the previous generation is still consulted so tokens
+// revoked during a rebuild are not missed. Revocations that could not be
+// stored are kept and written back once the cache recovers.
+type localBlacklist struct {
+	mu       sync.Mutex
+	size     int
+	current  *bloomFilter
+	previous *bloomFilter
+	pending  map[string]pendingRevocation
+}
+
+// pendingRevocation is a revocation waiting to be written to the cache
+type pendingRevocation struct {
+	revokedAt int64
+	expiresAt time.Time
+}
+
+func newLocalBlacklist(size int) *localBlacklist {
+	return &localBlacklist{
+		size:     size,
+		current:  newBloomFilter(size, blacklistFalsePositiveRate),
+		previous: newBloomFilter(1, blacklistFalsePositiveRate),
+		pending:  make(map[string]pendingRevocation),
+	}
+}
+
+// initLocalBlacklist starts keeping the local blacklist in sync with the
+// cache, and writes revocations back whenever the breaker closes again
+func initLocalBlacklist(b *breaker) {
+	cfg := config.AppConfig.Cache
+	blacklistFilter = newLocalBlacklist(cfg.BlacklistFilterSize)
+	b.whenRecovered(func() {
+		if err := blacklistFilter.sync(); err != nil {
+			logger.Warn().Err(err).Msg("Failed to synchronize local token blacklist after cache recovery")
+		}
+	})
+	go blacklistFilter.run(cfg.BlacklistSyncInterval)
+}
+
+// run rebuilds the filter every interval while the cache is available
+func (b *localBlacklist) run(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if Available() {
+			if err := b.sync(); err != nil {
+				logger.Warn().Err(err).Msg("Failed to synchronize local token blacklist")
+			}
+		}
+		<-ticker.C
+	}
+}
+
+func (b *localBlacklist) add(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current.add(key)
+}
+
+// contains reports whether any of the keys may have been revoked
+func (b *localBlacklist) contains(keys ...string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, key := range keys {
+		if b.current.contains(key) || b.previous.contains(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// keep records a revocation the cache could not store
+func (b *localBlacklist) keep(key string, revokedAt int64, expiresAt time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current.add(key)
+	b.pending[key] = pendingRevocation{revokedAt: revokedAt, expiresAt: expiresAt}
+}
+
+// sync writes pending revocations to the cache, then rebuilds the filter
+// from the blacklisted keys found there
+func (b *localBlacklist) sync() error {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = make(map[string]pendingRevocation)
+	b.mu.Unlock()
+
+	var writeErr error
+	for key, revocation := range pending {
+		ttl := time.Until(revocation.expiresAt)
+		if ttl <= 0 {
+			continue
+		}
+		if writeErr == nil {
+			writeErr = SetWithTTL(key, revocation.revokedAt, ttl)
+			if writeErr == nil {
+				continue
+			}
+		}
+		b.keep(key, revocation.revokedAt, revocation.expiresAt)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if len(pending) > 0 {
+		logger.Info().Int("tokens", len(pending)).Msg("Wrote back tokens revoked while the cache was unavailable")
+	}
+
+	filter := newBloomFilter(b.size, blacklistFalsePositiveRate)
+	count := 0
+	err := Backend.Scan(ctx, TokenBlacklistPrefix+":", func(keys []string) error {
+		for _, key := range keys {
+			filter.add(key)
+		}
+		count += len(keys)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if count > b.size {
+		logger.Warn().
+			Int("tokens", count).
+			Int("filter_size", b.size).
+			Msg("Blacklist exceeds CACHE_BLACKLIST_FILTER_SIZE, local fallback will reject more valid tokens")
+	}
+
+	b.mu.Lock()
+	b.previous, b.current = b.current, filter
+	b.mu.Unlock()
+
+	logger.Debug().Int("tokens", count).Msg("Local token blacklist synchronized")
+	return nil
+}
@@ -0,0 +1,338 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCacheUnavailable is returned without contacting the backend while its
+// circuit breaker is open
+var ErrCacheUnavailable = errors.New("cache unavailable")
+
+// storeBreaker guards the Redis backend; it is nil for backends that cannot fail
+var storeBreaker *breaker
+
+// breaker stops calls to a backend after consecutive failures, so requests
+// fail fast instead of each waiting for a timeout. While open, it pings the
+// backend in the background and closes again as soon as a ping succeeds.
+type breaker struct {
+	mu            sync.Mutex
+	threshold     int
+	retryInterval time.Duration
+	ping          func(ctx context.Context) error
+	failures      int
+	open          bool
+	since         time.Time // When the breaker last opened or closed
+	lastErr       error
+	onRecover     []func()
+	done          chan struct{}
+}
+
+func newBreaker(threshold int, retryInterval time.Duration, ping func(ctx context.Context) error) *breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	if retryInterval <= 0 {
+		retryInterval = time.Second
+	}
+	metrics.CacheAvailable.Set(1)
+	return &breaker{
+		threshold:     threshold,
+		retryInterval: retryInterval,
+		ping:          ping,
+		since:         time.Now(),
+		done:          make(chan struct{}),
+	}
+}
+
+// allow returns ErrCacheUnavailable while the breaker is open
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		return ErrCacheUnavailable
+	}
+	return nil
+}
+
+// record counts a call's outcome, opening the breaker once failures reach the threshold
+func (b *breaker) record(err error) {
+	if !isUnavailable(err) {
+		b.mu.Lock()
+		b.failures = 0
+		b.mu.Unlock()
+		return
+	}
+
+	b.mu.Lock()
+	b.failures++
+	b.lastErr = err
+	trip := !b.open && b.failures >= b.threshold
+	b.mu.Unlock()
+
+	if trip {
+		b.trip(err)
+	}
+}
+
+// trip opens the breaker and starts pinging the backend until it recovers
+func (b *breaker) trip(err error) {
+	b.mu.Lock()
+	if b.open {
+		b.mu.Unlock()
+		return
+	}
+	b.open = true
+	b.since = time.Now()
+	b.lastErr = err
+	b.mu.Unlock()
+
+	metrics.RecordCacheBreakerState(true)
+	logger.Error().Err(err).Dur("retry_interval", b.retryInterval).Msg("Cache unavailable, circuit breaker opened")
+	go b.reconnect()
+}
+
+// reconnect pings the backend every retry interval and closes the breaker on
+// the first success
+func (b *breaker) reconnect() {
+	ticker := time.NewTicker(b.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, b.retryInterval)
+		err := b.ping(pingCtx)
+		cancel()
+		if err != nil {
+			b.mu.Lock()
+			b.lastErr = err
+			b.mu.Unlock()
+			logger.Debug().Err(err).Msg("Cache still unavailable")
+			continue
+		}
+
+		b.mu.Lock()
+		downtime := time.Since(b.since)
+		b.open = false
+		b.failures = 0
+		b.since = time.Now()
+		b.lastErr = nil
+		hooks := b.onRecover
+		b.mu.Unlock()
+
+		metrics.RecordCacheBreakerState(false)
+		logger.Info().Dur("downtime", downtime).Msg("Cache reachable again, circuit breaker closed")
+		for _, hook := range hooks {
+			go hook()
+		}
+		return
+	}
+}
+
+// whenRecovered registers a function run each time the breaker closes again
+func (b *breaker) whenRecovered(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onRecover = append(b.onRecover, fn)
+}
+
+func (b *breaker) stop() {
+	select {
+	case <-b.done:
+	default:
+		close(b.done)
+	}
+}
+
+// isUnavailable tells failures of the backend apart from errors it returned
+// while working normally, such as incrementing a non-integer value
+func isUnavailable(err error) bool {
+	if err == nil || errors.Is(err, ErrNotInteger) {
+		return false
+	}
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		// A server still loading its data or a cluster without quorum cannot serve requests
+		for _, prefix := range []string{"LOADING", "CLUSTERDOWN", "MASTERDOWN", "TRYAGAIN"} {
+			if redis.HasErrorPrefix(err, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// breakerStore routes every call through a circuit breaker
+type breakerStore struct {
+	Store
+	breaker *breaker
+}
+
+func newBreakerStore(store Store, threshold int, retryInterval time.Duration) *breakerStore {
+	return &breakerStore{Store: store, breaker: newBreaker(threshold, retryInterval, store.Ping)}
+}
+
+func (s *breakerStore) call(fn func() error) error {
+	if err := s.breaker.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	s.breaker.record(err)
+	return err
+}
+
+func (s *breakerStore) Get(ctx context.Context, key string) (value []byte, found bool, err error) {
+	err = s.call(func() error {
+		value, found, err = s.Store.Get(ctx, key)
+		return err
+	})
+	return value, found, err
+}
+
+func (s *breakerStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.call(func() error { return s.Store.Set(ctx, key, value, ttl) })
+}
+
+func (s *breakerStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (stored bool, err error) {
+	err = s.call(func() error {
+		stored, err = s.Store.SetNX(ctx, key, value, ttl)
+		return err
+	})
+	return stored, err
+}
+
+func (s *breakerStore) Delete(ctx context.Context, keys ...string) error {
+	return s.call(func() error { return s.Store.Delete(ctx, keys...) })
+}
+
+func (s *breakerStore) DeleteIfEquals(ctx context.Context, key string, value []byte) (deleted bool, err error) {
+	err = s.call(func() error {
+		deleted, err = s.Store.DeleteIfEquals(ctx, key, value)
+		return err
+	})
+	return deleted, err
+}
+
+func (s *breakerStore) ExpireIfEquals(ctx context.Context, key string, value []byte, ttl time.Duration) (expired bool, err error) {
+	err = s.call(func() error {
+		expired, err = s.Store.ExpireIfEquals(ctx, key, value, ttl)
+		return err
+	})
+	return expired, err
+}
+
+func (s *breakerStore) SetTagged(ctx context.Context, key string, value []byte, ttl time.Duration, tagKeys ...string) error {
+	return s.call(func() error { return s.Store.SetTagged(ctx, key, value, ttl, tagKeys...) })
+}
+
+func (s *breakerStore) DeleteTagged(ctx context.Context, tagKey string) (keys []string, err error) {
+	err = s.call(func() error {
+		keys, err = s.Store.DeleteTagged(ctx, tagKey)
+		return err
+	})
+	return keys, err
+}
+
+func (s *breakerStore) TTL(ctx context.Context, key string) (ttl time.Duration, err error) {
+	err = s.call(func() error {
+		ttl, err = s.Store.TTL(ctx, key)
+		return err
+	})
+	return ttl, err
+}
+
+func (s *breakerStore) Incr(ctx context.Context, key string) (n int64, err error) {
+	err = s.call(func() error {
+		n, err = s.Store.Incr(ctx, key)
+		return err
+	})
+	return n, err
+}
+
+func (s *breakerStore) Expire(ctx context.Context, key string, ttl time.Duration) (exists bool, err error) {
+	err = s.call(func() error {
+		exists, err = s.Store.Expire(ctx, key, ttl)
+		return err
+	})
+	return exists, err
+}
+
+func (s *breakerStore) Scan(ctx context.Context, prefix string, fn func(keys []string) error) error {
+	return s.call(func() error { return s.Store.Scan(ctx, prefix, fn) })
+}
+
+func (s *breakerStore) Size(ctx context.Context, keys ...string) (sizes []int64, err error) {
+	err = s.call(func() error {
+		sizes, err = s.Store.Size(ctx, keys...)
+		return err
+	})
+	return sizes, err
+}
+
+func (s *breakerStore) Pipeline() Pipeline {
+	return &breakerPipeline{Pipeline: s.Store.Pipeline(), store: s}
+}
+
+func (s *breakerStore) Ping(ctx context.Context) error {
+	return s.call(func() error { return s.Store.Ping(ctx) })
+}
+
+func (s *breakerStore) Close() error {
+	s.breaker.stop()
+	return s.Store.Close()
+}
+
+// breakerPipeline sends its commands only while the breaker is closed
+type breakerPipeline struct {
+	Pipeline
+	store *breakerStore
+}
+
+func (p *breakerPipeline) Exec(ctx context.Context) error {
+	return p.store.call(func() error { return p.Pipeline.Exec(ctx) })
+}
+
+// HealthStatus reports the state of the cache backend
+type HealthStatus struct {
+	Backend   string     `json:"backend"`
+	Status    string     `json:"status"`          // up, or down while the circuit breaker is open
+	Since     *time.Time `json:"since,omitempty"` // When the status last changed
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// Health returns the state of the cache backend
+func Health() HealthStatus {
+	status := HealthStatus{Backend: backendName, Status: "up"}
+	if storeBreaker == nil {
+		return status
+	}
+
+	storeBreaker.mu.Lock()
+	defer storeBreaker.mu.Unlock()
+	since := storeBreaker.since
+	status.Since = &since
+	if storeBreaker.open {
+		status.Status = "down"
+		if storeBreaker.lastErr != nil {
+			status.LastError = storeBreaker.lastErr.Error()
+		}
+	}
+	return status
+}
+
+// Available reports whether the cache backend is believed to be reachable
+func Available() bool {
+	return storeBreaker == nil || storeBreaker.allow() == nil
+}
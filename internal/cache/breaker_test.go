@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"goapi-starter/internal/config"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errConnRefused = errors.New("dial tcp: connection refused")
+
+// flakyStore fails every call while down, like an unreachable Redis
+type flakyStore struct {
+	Store
+	down  atomic.Bool
+	calls atomic.Int64
+}
+
+func (s *flakyStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.calls.Add(1)
+	if s.down.Load() {
+		return nil, false, errConnRefused
+	}
+	return s.Store.Get(ctx, key)
+}
+
+func (s *flakyStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.calls.Add(1)
+	if s.down.Load() {
+		return errConnRefused
+	}
+	return s.Store.Set(ctx, key, value, ttl)
+}
+
+func (s *flakyStore) Ping(ctx context.Context) error {
+	if s.down.Load() {
+		return errConnRefused
+	}
+	return nil
+}
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	flaky := &flakyStore{Store: NewMemoryStore()}
+	store := newBreakerStore(flaky, 3, 10*time.Millisecond)
+	defer store.Close()
+
+	recovered := make(chan struct{}, 1)
+	store.breaker.whenRecovered(func() { recovered <- struct{}{} })
+
+	flaky.down.Store(true)
+	for i := 0; i < 3; i++ {
+		if _, _, err := store.Get(ctx, "key"); !errors.Is(err, errConnRefused) {
+			t.Fatalf("call %d: err = %v", i, err)
+		}
+	}
+	if _, _, err := store.Get(ctx, "key"); !errors.Is(err, ErrCacheUnavailable) {
+		t.Fatalf("expected ErrCacheUnavailable once the breaker is open, got %v", err)
+	}
+	if calls := flaky.calls.Load(); calls != 3 {
+		t.Errorf("backend called %d times, want 3", calls)
+	}
+
+	flaky.down.Store(false)
+	select {
+	case <-recovered:
+	case <-time.After(time.Second):
+		t.Fatal("breaker did not close after the backend recovered")
+	}
+	if err := store.Set(ctx, "key", []byte("value"), 0); err != nil {
+		t.Errorf("Set after recovery: %v", err)
+	}
+}
+
+func TestBlacklistFallback(t *testing.T) {
+	previousBackend, previousBreaker, previousFilter := Backend, storeBreaker, blacklistFilter
+	previousPolicy := config.AppConfig.Cache.BlacklistFailurePolicy
+	defer func() {
+		Backend, storeBreaker, blacklistFilter = previousBackend, previousBreaker, previousFilter
+		config.AppConfig.Cache.BlacklistFailurePolicy = previousPolicy
+	}()
+
+	flaky := &flakyStore{Store: NewMemoryStore()}
+	guarded := newBreakerStore(flaky, 1, time.Hour)
+	defer guarded.Close()
+	Backend, storeBreaker = guarded, guarded.breaker
+	blacklistFilter = newLocalBlacklist(100)
+
+	revokedEarlier := "revoked-earlier-token"
+	BlacklistToken("access", revokedEarlier, time.Minute)
+	if err := blacklistFilter.sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	flaky.down.Store(true)
+	revokedDuringOutage := "revoked-during-outage"
+	if err := BlacklistToken("access", revokedDuringOutage, time.Minute); err != nil {
+		t.Fatalf("BlacklistToken during an outage: %v", err)
+	}
+
+	for _, token := range []string{revokedEarlier, revokedDuringOutage} {
+		if blacklisted, err := IsAccessTokenBlacklisted(token); !blacklisted || err != nil {
+			t.Errorf("%s: blacklisted = %v, %v during an outage", token, blacklisted, err)
+		}
+	}
+
+	config.AppConfig.Cache.BlacklistFailurePolicy = string(FailOpen)
+	if blacklisted, err := IsAccessTokenBlacklisted("never-revoked-token"); blacklisted || err != nil {
+		t.Errorf("open policy: blacklisted = %v, %v", blacklisted, err)
+	}
+	config.AppConfig.Cache.BlacklistFailurePolicy = string(FailClosed)
+	if _, err := IsAccessTokenBlacklisted("never-revoked-token"); err == nil {
+		t.Error("closed policy: expected an error for a token the local blacklist does not know")
+	}
+
+	// Recover by hand; the retry interval is too long for the breaker to do it
+	flaky.down.Store(false)
+	guarded.breaker.mu.Lock()
+	guarded.breaker.open = false
+	guarded.breaker.mu.Unlock()
+	if err := blacklistFilter.sync(); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := flaky.Store.Get(ctx, blacklistKey("access", revokedDuringOutage)); !found {
+		t.Error("expected the revocation made during the outage to be written back")
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	f := newBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.add(string(rune('a'+i%26)) + time.Duration(i).String())
+	}
+	for i := 0; i < 1000; i++ {
+		if !f.contains(string(rune('a'+i%26)) + time.Duration(i).String()) {
+			t.Fatalf("entry %d missing", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if f.contains("absent-" + time.Duration(i).String()) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 300 {
+		t.Errorf("%d false positives in 10000, expected around 100", falsePositives)
+	}
+}
@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"strings"
+	"time"
+)
+
+// Set stores a value in the cache with the default TTL
+func Set(key string, value interface{}) error {
+	metrics.RecordCacheOperation("set", "default")
+	return SetWithTTL(key, value, config.AppConfig.Redis.CacheTTL)
+}
+
+// SetWithTTL stores a value in the cache with a specific TTL. Tags, such as
+// "products" or "product:42", let InvalidateTag remove the key later.
+func SetWithTTL(key string, value interface{}, ttl time.Duration, tags ...string) error {
+	metrics.RecordCacheOperation("set", "custom_ttl")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("set", time.Since(startTime))
+	}()
+
+	// Serialize the value with the configured codec
+	encoded, err := encodeValue(value)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to marshal value for caching")
+		return err
+	}
+
+	// Record the size of the cached object
+	keyPrefix := strings.Split(key, ":")[0]
+	metrics.RecordCacheSize(keyPrefix, len(encoded))
+
+	if len(tags) > 0 {
+		err = Backend.SetTagged(ctx, key, encoded, ttl, tagKeys(tags)...)
+	} else {
+		err = Backend.Set(ctx, key, encoded, ttl)
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to set cache value")
+		return err
+	}
+	invalidate(key)
+
+	logger.Debug().Str("key", key).Dur("ttl", ttl).Msg("Successfully cached value")
+	return nil
+}
+
+// SetIfNotExists stores a value with a specific TTL only if the key does not
+// exist yet. It reports whether the value was stored.
+func SetIfNotExists(key string, value interface{}, ttl time.Duration) (bool, error) {
+	metrics.RecordCacheOperation("setnx", "custom_ttl")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("setnx", time.Since(startTime))
+	}()
+
+	encoded, err := encodeValue(value)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to marshal value for caching")
+		return false, err
+	}
+
+	stored, err := Backend.SetNX(ctx, key, encoded, ttl)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to set cache value")
+		return false, err
+	}
+	if stored {
+		invalidate(key)
+	}
+
+	return stored, nil
+}
+
+// Get retrieves a value from the cache. Keys cached as not found return
+// ErrNotFound.
+func Get(key string, dest interface{}) (bool, error) {
+	metrics.RecordCacheOperation("get", "default")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("get", time.Since(startTime))
+	}()
+
+	// Get from the in-process cache or the backend
+	val, exists, err := getRaw(key)
+	if err == nil && !exists {
+		// Key does not exist
+		logger.Debug().Str("key", key).Msg("Cache miss")
+		metrics.RecordCacheResult("miss")
+		return false, nil
+	}
+	if err != nil {
+		// Error occurred
+		logger.Error().Err(err).Str("key", key).Msg("Error retrieving from cache")
+		metrics.RecordCacheResult("error")
+		return false, err
+	}
+
+	if isNotFoundValue(val) {
+		logger.Debug().Str("key", key).Msg("Cache hit for a not found result")
+		metrics.RecordCacheResult("negative_hit")
+		return false, ErrNotFound
+	}
+
+	// Unmarshal the value
+	err = decodeValue(val, dest)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to unmarshal cached value")
+		metrics.RecordCacheResult("unmarshal_error")
+		return false, err
+	}
+
+	logger.Debug().Str("key", key).Msg("Cache hit")
+	metrics.RecordCacheResult("hit")
+	return true, nil
+}
+
+// Delete removes a value from the cache
+func Delete(key string) error {
+	metrics.RecordCacheOperation("delete", "default")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("delete", time.Since(startTime))
+	}()
+
+	err := Backend.Delete(ctx, key)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to delete from cache")
+		return err
+	}
+	invalidate(key)
+
+	logger.Debug().Str("key", key).Msg("Successfully deleted from cache")
+	return nil
+}
+
+// DeleteMany removes several values from the cache in a single round trip
+func DeleteMany(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	metrics.RecordCacheOperation("delete", "many")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("delete", time.Since(startTime))
+	}()
+
+	err := Backend.Delete(ctx, keys...)
+	if err != nil {
+		logger.Error().Err(err).Int("keys", len(keys)).Msg("Failed to delete from cache")
+		return err
+	}
+	invalidate(keys...)
+
+	logger.Debug().Int("keys", len(keys)).Msg("Successfully deleted from cache")
+	return nil
+}
+
+// GetTTL returns the remaining time-to-live of a key
+func GetTTL(key string) (time.Duration, error) {
+	metrics.RecordCacheOperation("ttl", "default")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("ttl", time.Since(startTime))
+	}()
+
+	ttl, err := Backend.TTL(ctx, key)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to get TTL from cache")
+		return 0, err
+	}
+
+	return ttl, nil
+}
+
+// Extend resets the time-to-live of an existing key. It reports whether the
+// key still existed.
+func Extend(key string, ttl time.Duration) (bool, error) {
+	metrics.RecordCacheOperation("expire", "custom_ttl")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("expire", time.Since(startTime))
+	}()
+
+	exists, err := Backend.Expire(ctx, key, ttl)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to extend cache TTL")
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Increment atomically increments the integer value of a key and returns the new value
+func Increment(key string) (int64, error) {
+	metrics.RecordCacheOperation("incr", "default")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("incr", time.Since(startTime))
+	}()
+
+	val, err := Backend.Incr(ctx, key)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to increment cache value")
+		return 0, err
+	}
+	invalidate(key)
+
+	return val, nil
+}
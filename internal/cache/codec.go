@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goapi-starter/internal/config"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/proto"
+)
+
+// Stored values start with a three-byte header: the encoding version, the
+// codec and the compression. Values written before the header existed came
+// from json.Marshal, which never emits leading whitespace or control
+// characters, so a first byte of 0x20 or above marks them as plain JSON.
+const (
+	encodingVersion byte = 1
+	headerSize           = 3
+
+	compressionNone byte = 0
+	compressionZstd byte = 1
+)
+
+var (
+	// ErrUnknownEncoding is returned for values written by a newer or unknown format
+	ErrUnknownEncoding = errors.New("unknown cache value encoding")
+	// ErrNotProtoMessage is returned when a protobuf value is decoded into a non-protobuf type
+	ErrNotProtoMessage = errors.New("value is not a protobuf message")
+)
+
+// Codec serializes cached values. Each codec has a fixed ID, recorded in
+// every value it writes, so entries stay readable after CACHE_CODEC changes.
+type Codec interface {
+	ID() byte
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// JSONCodec encodes values with encoding/json
+	JSONCodec Codec = jsonCodec{}
+	// MsgpackCodec encodes values as MessagePack
+	MsgpackCodec Codec = msgpackCodec{}
+	// ProtobufCodec encodes protobuf messages; it is picked automatically for
+	// values implementing proto.Message, whatever CACHE_CODEC says
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+var codecs = map[byte]Codec{
+	JSONCodec.ID():     JSONCodec,
+	MsgpackCodec.ID():  MsgpackCodec,
+	ProtobufCodec.ID(): ProtobufCodec,
+}
+
+// CodecByName returns the codec selected by a CACHE_CODEC value
+func CodecByName(name string) (Codec, error) {
+	for _, codec := range codecs {
+		if codec.Name() == name {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown cache codec %q", name)
+}
+
+// zstd encoders and decoders are safe for concurrent EncodeAll and DecodeAll calls
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// encodeValue serializes v with its codec and compresses the result once it
+// reaches CACHE_COMPRESSION_THRESHOLD bytes
+func encodeValue(v interface{}) ([]byte, error) {
+	codec := defaultCodec()
+	if _, ok := v.(proto.Message); ok {
+		codec = ProtobufCodec
+	}
+
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	compression := compressionNone
+	if threshold := config.AppConfig.Cache.CompressionThreshold; threshold > 0 && len(data) >= threshold {
+		if compressed := zstdEncoder.EncodeAll(data, nil); len(compressed) < len(data) {
+			data, compression = compressed, compressionZstd
+		}
+	}
+
+	value := make([]byte, 0, headerSize+len(data))
+	value = append(value, encodingVersion, codec.ID(), compression)
+	return append(value, data...), nil
+}
+
+// decodeValue reverses encodeValue, reading headerless values as JSON
+func decodeValue(data []byte, v interface{}) error {
+	if len(data) == 0 || data[0] >= 0x20 {
+		return json.Unmarshal(data, v)
+	}
+	if data[0] != encodingVersion || len(data) < headerSize {
+		return ErrUnknownEncoding
+	}
+
+	codec, ok := codecs[data[1]]
+	if !ok {
+		return ErrUnknownEncoding
+	}
+
+	payload := data[headerSize:]
+	switch data[2] {
+	case compressionNone:
+	case compressionZstd:
+		var err error
+		if payload, err = zstdDecoder.DecodeAll(payload, nil); err != nil {
+			return err
+		}
+	default:
+		return ErrUnknownEncoding
+	}
+
+	return codec.Unmarshal(payload, v)
+}
+
+func defaultCodec() Codec {
+	if codec, err := CodecByName(config.AppConfig.Cache.Codec); err == nil {
+		return codec
+	}
+	return JSONCodec
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte                                   { return 1 }
+func (jsonCodec) Name() string                               { return "json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type protobufCodec struct{}
+
+func (protobufCodec) ID() byte     { return 3 }
+func (protobufCodec) Name() string { return "protobuf" }
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+	return proto.Marshal(message)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return ErrNotProtoMessage
+	}
+	return proto.Unmarshal(data, message)
+}
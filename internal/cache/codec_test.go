@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"encoding/json"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/money"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"gorm.io/gorm"
+)
+
+func TestCodecsRoundTrip(t *testing.T) {
+	previous := config.AppConfig.Cache
+	defer func() { config.AppConfig.Cache = previous }()
+
+	categoryID := uint(7)
+	product := models.DummyProduct{
+		ID:          42,
+		Name:        "Lamp",
+		Description: strings.Repeat("bright ", 300),
+		Price:       money.MustParse("19.99"),
+		Currency:    "USD",
+		CreatedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		CategoryID:  &categoryID,
+		Tags:        []models.Tag{{ID: 3, Name: "desk"}},
+		DeletedAt:   gorm.DeletedAt{Time: time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC), Valid: true},
+	}
+
+	for _, codec := range []string{"json", "msgpack"} {
+		for _, threshold := range []int{0, 64} {
+			config.AppConfig.Cache.Codec = codec
+			config.AppConfig.Cache.CompressionThreshold = threshold
+
+			data, err := encodeValue(product)
+			if err != nil {
+				t.Fatalf("%s/%d: %v", codec, threshold, err)
+			}
+			if wantCompressed := threshold > 0; (data[2] == compressionZstd) != wantCompressed {
+				t.Errorf("%s/%d: compression byte = %d", codec, threshold, data[2])
+			}
+
+			var decoded models.DummyProduct
+			if err := decodeValue(data, &decoded); err != nil {
+				t.Fatalf("%s/%d: %v", codec, threshold, err)
+			}
+			if decoded.Name != product.Name || decoded.Description != product.Description ||
+				decoded.Price.String() != product.Price.String() || !decoded.CreatedAt.Equal(product.CreatedAt) ||
+				decoded.CategoryID == nil || *decoded.CategoryID != categoryID ||
+				len(decoded.Tags) != 1 || decoded.Tags[0].Name != "desk" || !decoded.DeletedAt.Time.Equal(product.DeletedAt.Time) {
+				t.Errorf("%s/%d: decoded %+v", codec, threshold, decoded)
+			}
+		}
+	}
+}
+
+func TestDecodeLegacyAndProtobufValues(t *testing.T) {
+	var userID string
+	if err := decodeValue([]byte(`"abc"`), &userID); err != nil || userID != "abc" {
+		t.Errorf("legacy JSON decoded to %q, %v", userID, err)
+	}
+	if err := decodeValue([]byte{9, 1, 0}, &userID); err != ErrUnknownEncoding {
+		t.Errorf("unknown version: %v, want ErrUnknownEncoding", err)
+	}
+
+	data, err := encodeValue(wrapperspb.String("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[1] != ProtobufCodec.ID() {
+		t.Errorf("protobuf messages should use the protobuf codec, got %d", data[1])
+	}
+	decoded := &wrapperspb.StringValue{}
+	if err := decodeValue(data, decoded); err != nil || !proto.Equal(decoded, wrapperspb.String("hello")) {
+		t.Errorf("decoded %v, %v", decoded, err)
+	}
+}
+
+func TestMsgpackReadsLegacyValues(t *testing.T) {
+	// The earlier codec stored MessagePack of each value's JSON form
+	product := models.DummyProduct{
+		ID:        42,
+		Name:      "Lamp",
+		Price:     money.MustParse("19.90"),
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:      []models.Tag{{ID: 3, Name: "desk"}},
+	}
+	text, _ := json.Marshal(product)
+	var generic interface{}
+	json.Unmarshal(text, &generic)
+	legacy, err := msgpack.Marshal(generic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded models.DummyProduct
+	if err := decodeValue(append([]byte{encodingVersion, MsgpackCodec.ID(), compressionNone}, legacy...), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Name != "Lamp" || decoded.Price.String() != "19.90" || !decoded.CreatedAt.Equal(product.CreatedAt) ||
+		len(decoded.Tags) != 1 || decoded.Tags[0].Name != "desk" {
+		t.Errorf("decoded %+v", decoded)
+	}
+}
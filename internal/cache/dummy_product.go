@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"fmt"
+	"goapi-starter/internal/logger"
+)
+
+const (
+	// DummyProductsTag is attached to every cached list page and search result
+	DummyProductsTag = "products"
+
+	// invalidationChunkSize bounds the number of tags invalidated per call
+	invalidationChunkSize = 500
+)
+
+// DummyProductKey returns the cache key of a single dummy product
+func DummyProductKey(id uint) string {
+	return fmt.Sprintf("dummy_product:%d", id)
+}
+
+// DummyProductTag returns the tag of every cached entry holding the product
+func DummyProductTag(id uint) string {
+	return fmt.Sprintf("product:%d", id)
+}
+
+// InvalidateDummyProductList drops every cached list page and search result,
+// regardless of the filters they were built from
+func InvalidateDummyProductList() {
+	if err := InvalidateTag(DummyProductsTag); err != nil {
+		logger.Warn().Err(err).Msg("Failed to invalidate dummy products list cache")
+	}
+}
+
+// InvalidateDummyProducts drops the cached copies of the given products,
+// chunked so a change touching thousands of products stays cheap, and the
+// list cache once
+func InvalidateDummyProducts(ids []uint) {
+	for start := 0; start < len(ids); start += invalidationChunkSize {
+		end := start + invalidationChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		tags := make([]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			tags = append(tags, DummyProductTag(id))
+		}
+		if err := InvalidateTags(tags...); err != nil {
+			logger.Warn().Err(err).Int("tags", len(tags)).Msg("Failed to invalidate dummy products in cache")
+		}
+	}
+
+	InvalidateDummyProductList()
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"sync"
+	"time"
+)
+
+const (
+	// leaderLockPrefix prefixes the lock names of leader elections
+	leaderLockPrefix = "leader:"
+	// defaultLeaderLease is used when an election is started without a lease
+	defaultLeaderLease = 15 * time.Second
+)
+
+// Election keeps at most one instance leader for a name at any time. Each
+// instance campaigns by trying to take the election's lock; the holder is the
+// leader for as long as it keeps renewing the lease.
+//
+// Leadership is not fenced: a leader stalled past its lease may briefly
+// overlap with its successor before it notices the loss, so work done as
+// leader must tolerate running twice. Use Locker directly and check
+// Lock.Token where writes need fencing.
+type Election struct {
+	locker *Locker
+	name   string
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	lease *Lock
+}
+
+// Campaign starts competing for leadership of name until ctx is cancelled.
+// A leader that crashes is replaced within ttl; one that shuts down cleanly
+// hands over right away.
+func (l *Locker) Campaign(ctx context.Context, name string, ttl time.Duration) *Election {
+	if ttl < time.Millisecond {
+		ttl = defaultLeaderLease
+	}
+	e := &Election{locker: l, name: name, ttl: ttl}
+	go e.run(ctx)
+	return e
+}
+
+// Leading returns a context that lives as long as this instance's current
+// leadership, and whether the instance leads at all. Work done as leader
+// should run under that context, which is cancelled if leadership is lost.
+func (e *Election) Leading() (context.Context, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.lease == nil {
+		return nil, false
+	}
+	return e.lease.Context(), true
+}
+
+// IsLeader reports whether this instance currently leads the election
+func (e *Election) IsLeader() bool {
+	_, leader := e.Leading()
+	return leader
+}
+
+func (e *Election) run(ctx context.Context) {
+	for {
+		lock, err := e.locker.TryAcquire(ctx, leaderLockPrefix+e.name, e.ttl)
+		switch {
+		case err == nil:
+			e.lead(ctx, lock)
+		case !errors.Is(err, ErrLockHeld) && ctx.Err() == nil:
+			logger.Debug().Err(err).Str("election", e.name).Msg("Failed to campaign for leadership")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.ttl / 3):
+		}
+	}
+}
+
+// lead holds leadership until the lease is lost or the campaign ends
+func (e *Election) lead(ctx context.Context, lock *Lock) {
+	e.setLease(lock)
+	logger.Info().Str("election", e.name).Msg("Elected leader")
+
+	<-lock.Context().Done()
+	e.setLease(nil)
+
+	if ctx.Err() != nil {
+		// Shutting down: free the lock so another instance takes over at once
+		lock.Release()
+		logger.Info().Str("election", e.name).Msg("Stepped down as leader")
+		return
+	}
+	logger.Warn().Str("election", e.name).Msg("Lost leadership")
+}
+
+func (e *Election) setLease(lock *Lock) {
+	e.mu.Lock()
+	e.lease = lock
+	e.mu.Unlock()
+	metrics.RecordLeadership(e.name, lock != nil)
+}
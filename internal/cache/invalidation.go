@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// InvalidationChannel is the pub/sub channel, under the cache namespace, on
+// which instances announce changed keys so the others can evict them from
+// their L1 cache
+const InvalidationChannel = "cache:invalidate"
+
+// invalidationChannel returns InvalidationChannel within CACHE_NAMESPACE
+func invalidationChannel() string {
+	if namespace := config.AppConfig.Cache.Namespace; namespace != "" {
+		return namespace + ":" + InvalidationChannel
+	}
+	return InvalidationChannel
+}
+
+// instanceID tells this instance's invalidation messages apart from others'
+var instanceID = newInstanceID()
+
+// invalidationMessage lists changed keys
+type invalidationMessage struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// initLocalCache creates the L1 cache and starts listening for invalidations
+func initLocalCache() {
+	cfg := config.AppConfig.Cache
+	if !cfg.L1Enabled {
+		return
+	}
+
+	l1 = newLocalCache(cfg.L1MaxEntries, cfg.L1TTL)
+	l1Prefixes = cfg.L1Prefixes
+	go listenForInvalidations()
+
+	logger.Info().
+		Int("max_entries", cfg.L1MaxEntries).
+		Dur("ttl", cfg.L1TTL).
+		Strs("prefixes", cfg.L1Prefixes).
+		Msg("In-process cache enabled")
+}
+
+// invalidate evicts changed keys from this instance's L1 cache right away and
+// tells the other instances to do the same
+func invalidate(keys ...string) {
+	if l1 == nil {
+		return
+	}
+
+	var local []string
+	for _, key := range keys {
+		if cachedLocally(key) {
+			local = append(local, key)
+		}
+	}
+	if len(local) == 0 {
+		return
+	}
+
+	l1.delete(local...)
+	publishInvalidation(invalidationMessage{Origin: instanceID, Keys: local})
+}
+
+func publishInvalidation(msg invalidationMessage) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if err := RedisClient.Publish(ctx, invalidationChannel(), payload).Err(); err != nil {
+		logger.Warn().Err(err).Int("keys", len(msg.Keys)).Msg("Failed to publish cache invalidation")
+	}
+}
+
+// listenForInvalidations evicts keys changed by other instances. Messages
+// published while the subscription was down are lost, so the whole L1 cache
+// is cleared every time the subscription is (re)established.
+func listenForInvalidations() {
+	pubsub := RedisClient.Subscribe(ctx, invalidationChannel())
+	defer pubsub.Close()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if errors.Is(err, redis.ErrClosed) {
+				return
+			}
+			logger.Warn().Err(err).Msg("Cache invalidation subscription interrupted")
+			l1.clear()
+			time.Sleep(time.Second)
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			l1.clear()
+			logger.Debug().Str("channel", m.Channel).Msg("Subscribed to cache invalidations")
+		case *redis.Message:
+			var inv invalidationMessage
+			if err := json.Unmarshal([]byte(m.Payload), &inv); err != nil || inv.Origin == instanceID {
+				continue
+			}
+			l1.delete(inv.Keys...)
+		}
+	}
+}
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,287 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"math"
+	mathrand "math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+const (
+	// loadLockPrefix prefixes the keys locking a cache entry while it is loaded
+	loadLockPrefix = "lock:"
+	// loadLockTTL bounds how long other instances wait for a load in progress
+	loadLockTTL = 5 * time.Second
+	// loadLockPoll is how often waiting instances look for the loaded value
+	loadLockPoll = 50 * time.Millisecond
+	// earlyExpiryBeta scales probabilistic early expiration; above 1 favors earlier refreshes
+	earlyExpiryBeta = 1.0
+)
+
+var (
+	// loads collapses concurrent misses for the same key within this instance
+	loads singleflight.Group
+	// refreshing tracks keys with a background refresh in flight
+	refreshing sync.Map
+)
+
+// loadedEntry is the stored form of values written by GetOrLoad. The store TTL
+// outlives SoftExpiry by the stale window, during which the value is still
+// served while a single worker refreshes it.
+type loadedEntry struct {
+	Value      json.RawMessage `json:"v"`
+	SoftExpiry int64           `json:"e"` // Unix milliseconds after which the value is stale
+	Delta      int64           `json:"d"` // Milliseconds the loader took, scaling early expiry
+}
+
+// GetOrLoad returns the cached value of key, calling loader to produce it on
+// a miss and caching the result for ttl.
+//
+// Concurrent misses are collapsed into one load per instance, and a short
+// lock in the store makes other instances wait for that load instead of running
+// their own. Once ttl has passed, the stale value keeps being served for
+// REDIS_CACHE_STALE_TTL while one worker refreshes it in the background.
+// Entries may also be refreshed shortly before they expire, with a
+// probability that grows as expiry approaches and with the cost of the load,
+// so hot keys are renewed before they ever miss. Loaded values are stored
+// with tags, as in SetWithTTL.
+//
+// A loader returning gorm.ErrRecordNotFound caches the key as not found for
+// CACHE_NEGATIVE_TTL_SECONDS, with the same tags; until then GetOrLoad
+// returns ErrNotFound without calling the loader.
+//
+// While the cache is unavailable, misses are loaded from the source as usual,
+// or fail with ErrCacheUnavailable when CACHE_DATA_FAILURE_POLICY is closed.
+func GetOrLoad[T any](key string, ttl time.Duration, loader func() (T, error), tags ...string) (T, error) {
+	metrics.RecordCacheOperation("get_or_load", "default")
+
+	var value T
+	entry, found, notFound := getEntry(key)
+	if notFound {
+		metrics.RecordCacheResult("negative_hit")
+		return value, ErrNotFound
+	}
+	if found {
+		if err := json.Unmarshal(entry.Value, &value); err == nil {
+			now := time.Now()
+			switch {
+			case now.UnixMilli() >= entry.SoftExpiry:
+				metrics.RecordCacheResult("stale")
+				refreshInBackground(key, ttl, loader, tags)
+			case expiresEarly(entry, now):
+				metrics.RecordCacheResult("early_refresh")
+				refreshInBackground(key, ttl, loader, tags)
+			default:
+				metrics.RecordCacheResult("hit")
+			}
+			return value, nil
+		}
+		logger.Warn().Str("key", key).Msg("Failed to unmarshal cached value, reloading")
+	}
+
+	if !Available() {
+		if DataPolicy() == FailClosed {
+			metrics.RecordCacheDegradedDecision("data", "rejected")
+			return value, ErrCacheUnavailable
+		}
+		metrics.RecordCacheDegradedDecision("data", "allowed")
+	}
+
+	metrics.RecordCacheResult("miss")
+	result, err, shared := loads.Do(key, func() (interface{}, error) {
+		return loadWithLock(key, ttl, loader, tags)
+	})
+	if shared {
+		metrics.RecordCacheResult("coalesced")
+	}
+	if err != nil {
+		return value, err
+	}
+	return result.(T), nil
+}
+
+// Prime stores a value in the format GetOrLoad reads, as if its loader had
+// just returned it. Write paths use it to keep the cache warm.
+func Prime(key string, value interface{}, ttl time.Duration, tags ...string) error {
+	return storeEntry(key, value, ttl, 0, tags)
+}
+
+// loadWithLock runs the loader under a lock in the store. When another instance holds
+// the lock, it waits for that instance's result and only loads by itself if
+// none appears before the lock would have expired.
+func loadWithLock[T any](key string, ttl time.Duration, loader func() (T, error), tags []string) (T, error) {
+	lockKey := loadLockPrefix + key
+	token := newLockToken()
+
+	acquired, err := Backend.SetNX(ctx, lockKey, []byte(token), loadLockTTL)
+	if err != nil {
+		logger.Warn().Err(err).Str("key", key).Msg("Failed to take cache load lock, loading without it")
+	}
+
+	if err == nil && !acquired {
+		deadline := time.Now().Add(loadLockTTL)
+		for time.Now().Before(deadline) {
+			time.Sleep(loadLockPoll)
+			entry, found, notFound := getEntry(key)
+			if notFound {
+				var value T
+				return value, ErrNotFound
+			}
+			if found {
+				var value T
+				if err := json.Unmarshal(entry.Value, &value); err == nil {
+					return value, nil
+				}
+				break
+			}
+		}
+		logger.Warn().Str("key", key).Msg("Timed out waiting for another instance to load cache entry")
+	}
+
+	if acquired {
+		defer func() {
+			if _, err := Backend.DeleteIfEquals(ctx, lockKey, []byte(token)); err != nil {
+				logger.Warn().Err(err).Str("key", key).Msg("Failed to release cache load lock")
+			}
+		}()
+	}
+
+	return loadAndStore(key, ttl, loader, tags)
+}
+
+// refreshInBackground reloads an entry unless this instance is already doing
+// so; the lock in the store keeps other instances from refreshing it at the same time
+func refreshInBackground[T any](key string, ttl time.Duration, loader func() (T, error), tags []string) {
+	if _, busy := refreshing.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+
+	go func() {
+		defer refreshing.Delete(key)
+
+		lockKey := loadLockPrefix + key
+		token := newLockToken()
+		acquired, err := Backend.SetNX(ctx, lockKey, []byte(token), loadLockTTL)
+		if err != nil || !acquired {
+			return
+		}
+		defer Backend.DeleteIfEquals(ctx, lockKey, []byte(token))
+
+		if _, err := loadAndStore(key, ttl, loader, tags); err != nil {
+			logger.Warn().Err(err).Str("key", key).Msg("Failed to refresh cache entry")
+		}
+	}()
+}
+
+// loadAndStore runs the loader and caches its result, timing the load for early expiry
+func loadAndStore[T any](key string, ttl time.Duration, loader func() (T, error), tags []string) (T, error) {
+	start := time.Now()
+	value, err := loader()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		SetNotFound(key, tags...)
+		return value, err
+	}
+	if err != nil {
+		return value, err
+	}
+
+	if err := storeEntry(key, value, ttl, time.Since(start), tags); err != nil {
+		logger.Warn().Err(err).Str("key", key).Msg("Failed to cache loaded value")
+	}
+	return value, nil
+}
+
+// expiresEarly decides whether to refresh an entry before its soft expiry,
+// following the XFetch algorithm: now - delta * beta * ln(rand) >= expiry
+func expiresEarly(entry loadedEntry, now time.Time) bool {
+	if entry.Delta <= 0 {
+		return false
+	}
+	gap := float64(entry.Delta) * earlyExpiryBeta * -math.Log(1-mathrand.Float64())
+	return float64(now.UnixMilli())+gap >= float64(entry.SoftExpiry)
+}
+
+func storeEntry(key string, value interface{}, ttl, delta time.Duration, tags []string) error {
+	metrics.RecordCacheOperation("set", "loaded")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("set", time.Since(startTime))
+	}()
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to marshal value for caching")
+		return err
+	}
+
+	data, err := encodeValue(loadedEntry{
+		Value:      raw,
+		SoftExpiry: time.Now().Add(ttl).UnixMilli(),
+		Delta:      delta.Milliseconds(),
+	})
+	if err != nil {
+		return err
+	}
+
+	keyPrefix := strings.Split(key, ":")[0]
+	metrics.RecordCacheSize(keyPrefix, len(data))
+
+	storeTTL := ttl + config.AppConfig.Redis.StaleTTL
+	if len(tags) > 0 {
+		err = Backend.SetTagged(ctx, key, data, storeTTL, tagKeys(tags)...)
+	} else {
+		err = Backend.Set(ctx, key, data, storeTTL)
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Failed to set cache value")
+		return err
+	}
+	invalidate(key)
+	return nil
+}
+
+// getEntry reads an entry written by GetOrLoad or Prime; notFound is true for
+// keys cached as not found. Errors are logged and reported as a miss so the
+// caller falls back to loading.
+func getEntry(key string) (entry loadedEntry, found bool, notFound bool) {
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("get", time.Since(startTime))
+	}()
+
+	data, exists, err := getRaw(key)
+	if err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Error retrieving from cache")
+		metrics.RecordCacheResult("error")
+		return loadedEntry{}, false, false
+	}
+	if !exists {
+		return loadedEntry{}, false, false
+	}
+	if isNotFoundValue(data) {
+		return loadedEntry{}, false, true
+	}
+
+	if err := decodeValue(data, &entry); err != nil || entry.Value == nil {
+		// Entries written before GetOrLoad existed are treated as a miss
+		return loadedEntry{}, false, false
+	}
+	return entry, true, false
+}
+
+func newLockToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
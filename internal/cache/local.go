@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"container/list"
+	"goapi-starter/internal/metrics"
+	"strings"
+	"sync"
+	"time"
+)
+
+// localCache is a bounded, TTL-limited LRU cache of raw backend values. Misses
+// are cached as well, so lookups that usually find nothing are answered
+// without a round trip.
+type localCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    map[string]*list.Element
+	order      *list.List // front is the most recently used
+	generation uint64     // incremented by every eviction, see fill
+}
+
+type localEntry struct {
+	key       string
+	value     []byte // nil for a cached miss
+	expiresAt time.Time
+}
+
+func newLocalCache(maxEntries int, ttl time.Duration) *localCache {
+	return &localCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// get returns a cached value; found is false when the L1 cache knows nothing
+// about the key and exists is false for a cached miss
+func (c *localCache) get(key string) (value []byte, exists, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, false
+	}
+	entry := elem.Value.(*localEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, false, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, entry.value != nil, true
+}
+
+// set caches a value, or a miss when value is nil, for at most the L1 TTL
+func (c *localCache) set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 || ttl > c.ttl {
+		ttl = c.ttl
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.value, entry.expiresAt = value, time.Now().Add(ttl)
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&localEntry{key: key, value: value, expiresAt: time.Now().Add(ttl)})
+	for c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+	metrics.CacheL1Entries.Set(float64(c.order.Len()))
+}
+
+// fill caches a value read from the backend, unless keys were evicted since
+// generation was taken before the read. The value may predate the change
+// that caused the eviction, and caching it would hide that change for the
+// whole L1 TTL.
+func (c *localCache) fill(key string, value []byte, generation uint64) {
+	c.mu.Lock()
+	stale := c.generation != generation
+	c.mu.Unlock()
+	if stale {
+		metrics.RecordCacheTierResult("l1", "stale_fill")
+		return
+	}
+	c.set(key, value, 0)
+}
+
+// currentGeneration returns the eviction counter to pass to fill
+func (c *localCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// delete evicts keys from the L1 cache
+func (c *localCache) delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.removeElement(elem)
+		}
+	}
+	metrics.CacheL1Entries.Set(float64(c.order.Len()))
+}
+
+// clear evicts every entry
+func (c *localCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	metrics.CacheL1Entries.Set(0)
+}
+
+func (c *localCache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*localEntry).key)
+}
+
+// l1 is the in-process cache, nil unless CACHE_L1_ENABLED is set
+var l1 *localCache
+
+// l1Prefixes are the key prefixes eligible for the L1 cache
+var l1Prefixes []string
+
+// cachedLocally reports whether a key goes through the L1 cache
+func cachedLocally(key string) bool {
+	if l1 == nil {
+		return false
+	}
+	prefix, _, _ := strings.Cut(key, ":")
+	for _, p := range l1Prefixes {
+		if p == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// getRaw reads a raw value through the L1 cache and then the backend. exists is
+// false when the key is not set.
+func getRaw(key string) (value []byte, exists bool, err error) {
+	local := cachedLocally(key)
+	var generation uint64
+	if local {
+		if value, exists, found := l1.get(key); found {
+			metrics.RecordCacheTierResult("l1", "hit")
+			return value, exists, nil
+		}
+		metrics.RecordCacheTierResult("l1", "miss")
+		generation = l1.currentGeneration()
+	}
+
+	value, exists, err = Backend.Get(ctx, key)
+	switch {
+	case err != nil:
+		metrics.RecordCacheTierResult("l2", "error")
+		return nil, false, err
+	case exists:
+		metrics.RecordCacheTierResult("l2", "hit")
+	default:
+		metrics.RecordCacheTierResult("l2", "miss")
+	}
+
+	if local {
+		l1.fill(key, value, generation)
+	}
+	return value, exists, nil
+}
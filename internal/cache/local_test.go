@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLocalCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLocalCache(2, time.Minute)
+	c.set("user:1", []byte("1"), 0)
+	c.set("user:2", []byte("2"), 0)
+	c.get("user:1")
+	c.set("user:3", []byte("3"), 0)
+
+	if _, _, found := c.get("user:2"); found {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"user:1", "user:3"} {
+		if value, exists, found := c.get(key); !found || !exists || string(value) != key[5:] {
+			t.Errorf("get(%q) = %q, %v, %v", key, value, exists, found)
+		}
+	}
+}
+
+func TestLocalCacheMissesAndExpiry(t *testing.T) {
+	c := newLocalCache(10, 20*time.Millisecond)
+	c.set("blacklist:access:a", nil, 0)
+	c.set("user:1", []byte("1"), time.Hour)
+
+	if _, exists, found := c.get("blacklist:access:a"); !found || exists {
+		t.Errorf("expected a cached miss, got exists=%v found=%v", exists, found)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	for i, key := range []string{"blacklist:access:a", "user:1"} {
+		if _, _, found := c.get(key); found {
+			t.Errorf("%d: expected %q to expire after the L1 TTL", i, key)
+		}
+	}
+
+	c.set("user:2", []byte("2"), 0)
+	c.delete("user:2")
+	if _, _, found := c.get("user:2"); found {
+		t.Error("expected user:2 to be deleted")
+	}
+}
+
+// pausingStore holds the first Get after reading the value, until released
+type pausingStore struct {
+	Store
+	once    sync.Once
+	read    chan struct{}
+	release chan struct{}
+}
+
+func (s *pausingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, exists, err := s.Store.Get(ctx, key)
+	s.once.Do(func() {
+		close(s.read)
+		<-s.release
+	})
+	return value, exists, err
+}
+
+func TestLocalCacheSkipsFillAfterConcurrentInvalidation(t *testing.T) {
+	store := &pausingStore{Store: NewMemoryStore(), read: make(chan struct{}), release: make(chan struct{})}
+	previousBackend, previousL1, previousPrefixes, previousClient := Backend, l1, l1Prefixes, RedisClient
+	Backend, l1, l1Prefixes = store, newLocalCache(10, time.Minute), []string{TokenBlacklistPrefix}
+	// Invalidations are published to an unreachable server and dropped
+	RedisClient = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer func() {
+		RedisClient.Close()
+		Backend, l1, l1Prefixes, RedisClient = previousBackend, previousL1, previousPrefixes, previousClient
+	}()
+
+	token := "token-revoked-during-read"
+	result := make(chan bool)
+	go func() {
+		revoked, _ := IsTokenBlacklisted("access", token)
+		result <- revoked
+	}()
+
+	// The check has read "not blacklisted" but not cached it yet
+	<-store.read
+	if err := BlacklistToken("access", token, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	close(store.release)
+
+	if <-result {
+		t.Error("expected the check that started before the revocation to pass")
+	}
+	if revoked, err := IsTokenBlacklisted("access", token); err != nil || !revoked {
+		t.Errorf("IsTokenBlacklisted after revocation = %v, %v, want true", revoked, err)
+	}
+}
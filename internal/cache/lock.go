@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"goapi-starter/internal/logger"
+	"strconv"
+	"time"
+)
+
+const (
+	// DistributedLockPrefix prefixes the keys of distributed locks and their fencing counters
+	DistributedLockPrefix = "dlock:"
+	// lockRetryInterval is how often Acquire retries a held lock
+	lockRetryInterval = 100 * time.Millisecond
+)
+
+var (
+	// ErrLockHeld is returned by TryAcquire when another holder has the lock
+	ErrLockHeld = errors.New("lock is held by another holder")
+	// ErrInvalidLease is returned for a lease too short to be renewed
+	ErrInvalidLease = errors.New("lock lease must be at least a millisecond")
+)
+
+// Locker hands out locks shared by every instance using the same store
+type Locker struct {
+	store Store
+}
+
+// NewLocker creates a locker on store; nil uses the application cache
+func NewLocker(store Store) *Locker {
+	return &Locker{store: store}
+}
+
+func (l *Locker) backend() Store {
+	if l.store != nil {
+		return l.store
+	}
+	return Backend
+}
+
+// Lock is a held lease on a named lock. The lease is renewed in the background
+// until Release is called or renewal fails, at which point Context is cancelled.
+type Lock struct {
+	store   Store
+	name    string
+	key     string
+	token   int64
+	ttl     time.Duration
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+// TryAcquire takes the named lock for a lease of ttl, or returns ErrLockHeld.
+// Every successful acquisition gets a fencing token greater than all earlier
+// ones, which writers can pass along so that a holder whose lease silently
+// expired is rejected by whatever it writes to.
+func (l *Locker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	if ttl < time.Millisecond {
+		return nil, ErrInvalidLease
+	}
+
+	store := l.backend()
+	key := lockKey(name)
+
+	// The counter never expires, so tokens keep increasing even after the lock does
+	token, err := store.Incr(ctx, key+":fence")
+	if err != nil {
+		return nil, err
+	}
+
+	acquired, err := store.SetNX(ctx, key, strconv.AppendInt(nil, token, 10), ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrLockHeld
+	}
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	lock := &Lock{
+		store:   store,
+		name:    name,
+		key:     key,
+		token:   token,
+		ttl:     ttl,
+		ctx:     lockCtx,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+	}
+	go lock.keepAlive()
+
+	logger.Debug().Str("lock", name).Int64("token", token).Dur("ttl", ttl).Msg("Acquired distributed lock")
+	return lock, nil
+}
+
+// Acquire waits until the named lock is free and takes it, or returns the
+// context's error
+func (l *Locker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	for {
+		lock, err := l.TryAcquire(ctx, name, ttl)
+		if !errors.Is(err, ErrLockHeld) {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// Token returns the lock's fencing token
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// Context is cancelled once the lease is lost or released; work done under
+// the lock should stop when it is
+func (l *Lock) Context() context.Context {
+	return l.ctx
+}
+
+// Release stops renewing the lease and frees the lock if it is still held
+func (l *Lock) Release() error {
+	l.cancel()
+	<-l.stopped
+
+	// The caller's context may be cancelled already; releasing must still happen
+	_, err := l.store.DeleteIfEquals(context.Background(), l.key, l.value())
+	if err != nil {
+		logger.Warn().Err(err).Str("lock", l.name).Msg("Failed to release distributed lock")
+	}
+	return err
+}
+
+// keepAlive extends the lease three times per ttl. The lock counts as lost as
+// soon as another holder has it, or once renewals have failed for a whole
+// lease, since it may have expired in the store by then.
+func (l *Lock) keepAlive() {
+	defer close(l.stopped)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	deadline := time.Now().Add(l.ttl)
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		held, err := l.store.ExpireIfEquals(context.Background(), l.key, l.value(), l.ttl)
+		switch {
+		case err == nil && held:
+			deadline = start.Add(l.ttl)
+			continue
+		case err == nil:
+			logger.Warn().Str("lock", l.name).Int64("token", l.token).Msg("Distributed lock lost to another holder")
+		case time.Now().Before(deadline):
+			logger.Warn().Err(err).Str("lock", l.name).Msg("Failed to renew distributed lock, retrying")
+			continue
+		default:
+			logger.Warn().Err(err).Str("lock", l.name).Int64("token", l.token).Msg("Distributed lock lease expired while renewals failed")
+		}
+
+		l.cancel()
+		return
+	}
+}
+
+func (l *Lock) value() []byte {
+	return strconv.AppendInt(nil, l.token, 10)
+}
+
+// lockKey hash-tags the name, so in Redis Cluster a lock and its fencing
+// counter share a slot
+func lockKey(name string) string {
+	return DistributedLockPrefix + "{" + name + "}"
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLockExclusiveWithFencingTokens(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+	locker := NewLocker(store)
+
+	first, err := locker.TryAcquire(ctx, "purge", 30*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := locker.TryAcquire(ctx, "purge", 30*time.Millisecond); !errors.Is(err, ErrLockHeld) {
+		t.Fatalf("second TryAcquire: err = %v, want ErrLockHeld", err)
+	}
+	if other, err := locker.TryAcquire(ctx, "cleanup", 30*time.Millisecond); err != nil {
+		t.Errorf("locks with other names should be independent: %v", err)
+	} else {
+		other.Release()
+	}
+
+	// The lease is renewed well past its TTL while held
+	time.Sleep(100 * time.Millisecond)
+	if first.Context().Err() != nil {
+		t.Fatal("lease was lost while being renewed")
+	}
+	if _, err := locker.TryAcquire(ctx, "purge", 30*time.Millisecond); !errors.Is(err, ErrLockHeld) {
+		t.Fatalf("lock expired while its lease was renewed: %v", err)
+	}
+
+	first.Release()
+	if first.Context().Err() == nil {
+		t.Error("expected the lock's context to be cancelled on release")
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	second, err := locker.Acquire(waitCtx, "purge", 30*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Release()
+	if second.Token() <= first.Token() {
+		t.Errorf("fencing token %d is not greater than the previous holder's %d", second.Token(), first.Token())
+	}
+}
+
+func TestLockLostToAnotherHolder(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+
+	lock, err := NewLocker(store).TryAcquire(ctx, "purge", 30*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+
+	// Someone else takes over the key, as after the lease expired during a pause
+	store.Set(ctx, lockKey("purge"), []byte("999"), time.Minute)
+
+	select {
+	case <-lock.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the lock's context to be cancelled once the lock was lost")
+	}
+}
+
+func TestElectionHasOneLeader(t *testing.T) {
+	store := NewMemoryStore()
+	defer store.Close()
+	locker := NewLocker(store)
+
+	ctxA, stopA := context.WithCancel(ctx)
+	defer stopA()
+	ctxB, stopB := context.WithCancel(ctx)
+	defer stopB()
+	a := locker.Campaign(ctxA, "job", 30*time.Millisecond)
+	b := locker.Campaign(ctxB, "job", 30*time.Millisecond)
+
+	waitFor(t, func() bool { return a.IsLeader() || b.IsLeader() })
+	if a.IsLeader() && b.IsLeader() {
+		t.Fatal("both instances are leaders")
+	}
+
+	leader, follower, stopLeader := a, b, stopA
+	if b.IsLeader() {
+		leader, follower, stopLeader = b, a, stopB
+	}
+
+	stopLeader()
+	waitFor(t, follower.IsLeader)
+	if leader.IsLeader() {
+		t.Error("the stopped instance still considers itself leader")
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
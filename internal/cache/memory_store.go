@@ -0,0 +1,390 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotInteger is returned by Incr when a key holds a non-integer value
+var ErrNotInteger = errors.New("value is not an integer")
+
+// memorySweepInterval is how often expired keys are removed from a MemoryStore
+const memorySweepInterval = time.Minute
+
+// MemoryStore is a Store kept in process memory. It behaves like the Redis
+// store, expiry included, so it can stand in for Redis in development, tests
+// and single-instance deployments.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	tags    map[string]memoryTagSet
+	done    chan struct{}
+	closed  sync.Once
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time // zero for keys without expiry
+}
+
+type memoryTagSet struct {
+	keys      map[string]struct{}
+	expiresAt time.Time // zero for sets without expiry
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// NewMemoryStore creates an empty store that sweeps expired keys until closed
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		tags:    make(map[string]memoryTagSet),
+		done:    make(chan struct{}),
+	}
+	go s.sweep()
+	return s
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookup(key)
+	if !ok {
+		return nil, false, nil
+	}
+	return bytes.Clone(entry.value), true, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, value, ttl)
+	return nil
+}
+
+func (s *MemoryStore) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lookup(key); ok {
+		return false, nil
+	}
+	s.set(key, value, ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+		delete(s.tags, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteIfEquals(_ context.Context, key string, value []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookup(key)
+	if !ok || !bytes.Equal(entry.value, value) {
+		return false, nil
+	}
+	delete(s.entries, key)
+	return true, nil
+}
+
+func (s *MemoryStore) ExpireIfEquals(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookup(key)
+	if !ok || !bytes.Equal(entry.value, value) {
+		return false, nil
+	}
+	return s.expire(key, ttl), nil
+}
+
+func (s *MemoryStore) SetTagged(_ context.Context, key string, value []byte, ttl time.Duration, tagKeys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, value, ttl)
+	now := time.Now()
+	for _, tagKey := range tagKeys {
+		set, ok := s.tags[tagKey]
+		if !ok || (!set.expiresAt.IsZero() && !now.Before(set.expiresAt)) {
+			set = memoryTagSet{keys: make(map[string]struct{}), expiresAt: now.Add(ttl)}
+		}
+		set.keys[key] = struct{}{}
+		switch expiresAt := now.Add(ttl); {
+		case ttl <= 0:
+			set.expiresAt = time.Time{}
+		case !set.expiresAt.IsZero() && set.expiresAt.Before(expiresAt):
+			set.expiresAt = expiresAt
+		}
+		s.tags[tagKey] = set
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteTagged(_ context.Context, tagKey string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, ok := s.tags[tagKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.tags, tagKey)
+
+	keys := make([]string, 0, len(set.keys))
+	for key := range set.keys {
+		delete(s.entries, key)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (s *MemoryStore) TTL(_ context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ttl(key), nil
+}
+
+func (s *MemoryStore) Incr(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.incr(key)
+}
+
+func (s *MemoryStore) Expire(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.expire(key, ttl), nil
+}
+
+func (s *MemoryStore) Scan(_ context.Context, prefix string, fn func(keys []string) error) error {
+	s.mu.Lock()
+	now := time.Now()
+	var keys []string
+	for key, entry := range s.entries {
+		if strings.HasPrefix(key, prefix) && !entry.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	for key, set := range s.tags {
+		if strings.HasPrefix(key, prefix) && (set.expiresAt.IsZero() || now.Before(set.expiresAt)) {
+			keys = append(keys, key)
+		}
+	}
+	s.mu.Unlock()
+
+	// fn runs without the lock so it may call back into the store
+	for start := 0; start < len(keys); start += scanBatchSize {
+		if err := fn(keys[start:min(start+scanBatchSize, len(keys))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Size(_ context.Context, keys ...string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sizes := make([]int64, len(keys))
+	for i, key := range keys {
+		if entry, ok := s.lookup(key); ok {
+			sizes[i] = int64(len(key) + len(entry.value))
+		} else if set, ok := s.tags[key]; ok {
+			sizes[i] = int64(len(key))
+			for member := range set.keys {
+				sizes[i] += int64(len(member))
+			}
+		}
+	}
+	return sizes, nil
+}
+
+func (s *MemoryStore) Pipeline() Pipeline {
+	return &memoryPipeline{store: s}
+}
+
+func (s *MemoryStore) Ping(context.Context) error {
+	return nil
+}
+
+// Close stops sweeping expired keys
+func (s *MemoryStore) Close() error {
+	s.closed.Do(func() { close(s.done) })
+	return nil
+}
+
+// lookup returns a live entry, dropping it if it has expired. Callers hold s.mu.
+func (s *MemoryStore) lookup(key string) (memoryEntry, bool) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	if entry.expired(time.Now()) {
+		delete(s.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+func (s *MemoryStore) set(key string, value []byte, ttl time.Duration) {
+	entry := memoryEntry{value: bytes.Clone(value)}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	s.entries[key] = entry
+}
+
+func (s *MemoryStore) ttl(key string) time.Duration {
+	entry, ok := s.lookup(key)
+	switch {
+	case !ok:
+		return TTLMissing
+	case entry.expiresAt.IsZero():
+		return TTLNoExpiry
+	}
+	return time.Until(entry.expiresAt)
+}
+
+func (s *MemoryStore) incr(key string) (int64, error) {
+	entry, _ := s.lookup(key)
+
+	var n int64
+	if entry.value != nil {
+		var err error
+		if n, err = strconv.ParseInt(string(entry.value), 10, 64); err != nil {
+			return 0, ErrNotInteger
+		}
+	}
+	n++
+
+	entry.value = strconv.AppendInt(nil, n, 10)
+	s.entries[key] = entry
+	return n, nil
+}
+
+func (s *MemoryStore) expire(key string, ttl time.Duration) bool {
+	entry, ok := s.lookup(key)
+	if !ok {
+		return false
+	}
+	if ttl <= 0 {
+		delete(s.entries, key)
+		return true
+	}
+	entry.expiresAt = time.Now().Add(ttl)
+	s.entries[key] = entry
+	return true
+}
+
+// sweep periodically drops expired keys that are never read again
+func (s *MemoryStore) sweep() {
+	ticker := time.NewTicker(memorySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, entry := range s.entries {
+				if entry.expired(now) {
+					delete(s.entries, key)
+				}
+			}
+			for key, set := range s.tags {
+				if !set.expiresAt.IsZero() && !now.Before(set.expiresAt) {
+					delete(s.tags, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// memoryPipeline applies its queued commands atomically on Exec
+type memoryPipeline struct {
+	store    *MemoryStore
+	commands []func() error
+}
+
+func (p *memoryPipeline) Set(key string, value []byte, ttl time.Duration) {
+	value = bytes.Clone(value)
+	p.commands = append(p.commands, func() error {
+		p.store.set(key, value, ttl)
+		return nil
+	})
+}
+
+func (p *memoryPipeline) Delete(keys ...string) {
+	p.commands = append(p.commands, func() error {
+		for _, key := range keys {
+			delete(p.store.entries, key)
+			delete(p.store.tags, key)
+		}
+		return nil
+	})
+}
+
+func (p *memoryPipeline) Incr(key string) *Result[int64] {
+	result := &Result[int64]{}
+	p.commands = append(p.commands, func() error {
+		result.val, result.err = p.store.incr(key)
+		return result.err
+	})
+	return result
+}
+
+func (p *memoryPipeline) Expire(key string, ttl time.Duration) *Result[bool] {
+	result := &Result[bool]{}
+	p.commands = append(p.commands, func() error {
+		result.val = p.store.expire(key, ttl)
+		return nil
+	})
+	return result
+}
+
+func (p *memoryPipeline) TTL(key string) *Result[time.Duration] {
+	result := &Result[time.Duration]{}
+	p.commands = append(p.commands, func() error {
+		result.val = p.store.ttl(key)
+		return nil
+	})
+	return result
+}
+
+func (p *memoryPipeline) Exec(context.Context) error {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+
+	var first error
+	for _, command := range p.commands {
+		if err := command(); err != nil && first == nil {
+			first = err
+		}
+	}
+	p.commands = nil
+	return first
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	defer s.Close()
+
+	if _, found, _ := s.Get(ctx, "missing"); found {
+		t.Error("expected a miss for a key that was never set")
+	}
+	if ttl, _ := s.TTL(ctx, "missing"); ttl != TTLMissing {
+		t.Errorf("TTL of a missing key = %v, want TTLMissing", ttl)
+	}
+
+	s.Set(ctx, "key", []byte("value"), 0)
+	if value, found, _ := s.Get(ctx, "key"); !found || string(value) != "value" {
+		t.Errorf("Get = %q, %v", value, found)
+	}
+	if ttl, _ := s.TTL(ctx, "key"); ttl != TTLNoExpiry {
+		t.Errorf("TTL of a key without expiry = %v, want TTLNoExpiry", ttl)
+	}
+
+	if stored, _ := s.SetNX(ctx, "key", []byte("other"), time.Minute); stored {
+		t.Error("SetNX overwrote an existing key")
+	}
+	if deleted, _ := s.DeleteIfEquals(ctx, "key", []byte("other")); deleted {
+		t.Error("DeleteIfEquals deleted a key holding another value")
+	}
+	if deleted, _ := s.DeleteIfEquals(ctx, "key", []byte("value")); !deleted {
+		t.Error("DeleteIfEquals kept a key holding the given value")
+	}
+
+	s.Incr(ctx, "counter")
+	s.Expire(ctx, "counter", time.Minute)
+	if n, _ := s.Incr(ctx, "counter"); n != 2 {
+		t.Errorf("Incr = %d, want 2", n)
+	}
+	if ttl, _ := s.TTL(ctx, "counter"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("Incr should keep the TTL, got %v", ttl)
+	}
+	s.Set(ctx, "text", []byte("abc"), 0)
+	if _, err := s.Incr(ctx, "text"); err != ErrNotInteger {
+		t.Errorf("Incr of a non-integer = %v, want ErrNotInteger", err)
+	}
+
+	s.Set(ctx, "short", []byte("x"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, found, _ := s.Get(ctx, "short"); found {
+		t.Error("expected the key to expire")
+	}
+}
+
+func TestMemoryStorePipeline(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	defer s.Close()
+
+	pipe := s.Pipeline()
+	pipe.Set("a", []byte("1"), time.Minute)
+	incr := pipe.Incr("a")
+	ttl := pipe.TTL("a")
+	pipe.Delete("b")
+	if incr.Val() != 0 {
+		t.Error("results should not be available before Exec")
+	}
+	if err := pipe.Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if incr.Val() != 2 || incr.Err() != nil {
+		t.Errorf("Incr = %d, %v", incr.Val(), incr.Err())
+	}
+	if ttl.Val() <= 0 || ttl.Val() > time.Minute {
+		t.Errorf("TTL = %v", ttl.Val())
+	}
+}
+
+func TestTokenBlacklistWithMemoryBackend(t *testing.T) {
+	previous := Backend
+	Backend = NewMemoryStore()
+	defer func() { Backend = previous }()
+
+	const token = "0123456789abcdef"
+	if blacklisted, err := IsAccessTokenBlacklisted(token); err != nil || blacklisted {
+		t.Fatalf("IsAccessTokenBlacklisted = %v, %v before blacklisting", blacklisted, err)
+	}
+	if err := BlacklistToken("access", token, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if blacklisted, err := IsAccessTokenBlacklisted(token); err != nil || !blacklisted {
+		t.Errorf("IsAccessTokenBlacklisted = %v, %v after blacklisting", blacklisted, err)
+	}
+	if blacklisted, _ := IsRefreshTokenBlacklisted(token); blacklisted {
+		t.Error("blacklisting an access token should not affect refresh tokens")
+	}
+}
+
+func TestInvalidateTag(t *testing.T) {
+	previous := Backend
+	Backend = NewMemoryStore()
+	defer func() { Backend = previous }()
+
+	SetWithTTL("dummy_products:list:a", 1, time.Minute, DummyProductsTag)
+	SetWithTTL("dummy_product:42", 2, time.Minute, DummyProductTag(42))
+	Prime("dummy_product:7", 3, time.Minute, DummyProductTag(7))
+
+	InvalidateDummyProducts([]uint{42})
+
+	var value int
+	for _, key := range []string{"dummy_products:list:a", "dummy_product:42"} {
+		if found, _ := Get(key, &value); found {
+			t.Errorf("expected %q to be invalidated", key)
+		}
+	}
+	if _, found, _ := getEntry("dummy_product:7"); !found {
+		t.Error("expected entries with other tags to be kept")
+	}
+
+	if err := InvalidateTag(DummyProductTag(7)); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := getEntry("dummy_product:7"); found {
+		t.Error("expected primed entries to be removed by their tag")
+	}
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackCodec stores values as MessagePack, encoding structs directly. Field
+// names come from the json tags, so cached values carry the same keys and
+// omit the same fields as with the JSON codec.
+type msgpackCodec struct{}
+
+func (msgpackCodec) ID() byte     { return 2 }
+func (msgpackCodec) Name() string { return "msgpack" }
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	err := dec.Decode(v)
+	if err == nil {
+		return nil
+	}
+	if legacyErr := unmarshalLegacyMsgpack(data, v); legacyErr != nil {
+		return err
+	}
+	return nil
+}
+
+// unmarshalLegacyMsgpack reads values written by the earlier codec, which
+// encoded each value's JSON form, so timestamps and nested values were
+// stored as their JSON text and maps rather than as the struct's own types
+func unmarshalLegacyMsgpack(data []byte, v interface{}) error {
+	var generic interface{}
+	if err := msgpack.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+
+	text, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(text, v)
+}
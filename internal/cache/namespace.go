@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// namespacedStore prefixes every key with a namespace, so several apps or
+// environments can share one Redis without touching each other's keys
+type namespacedStore struct {
+	Store
+	prefix string
+}
+
+// WithNamespace wraps a store so every key is stored under namespace, such as
+// "goapi:production". Keys passed in and returned are always unprefixed.
+func WithNamespace(store Store, namespace string) Store {
+	if namespace == "" {
+		return store
+	}
+	return &namespacedStore{Store: store, prefix: namespace + ":"}
+}
+
+func (s *namespacedStore) key(key string) string {
+	return s.prefix + key
+}
+
+func (s *namespacedStore) keys(keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.prefix + key
+	}
+	return prefixed
+}
+
+func (s *namespacedStore) strip(keys []string) []string {
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, s.prefix)
+	}
+	return keys
+}
+
+func (s *namespacedStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return s.Store.Get(ctx, s.key(key))
+}
+
+func (s *namespacedStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.Store.Set(ctx, s.key(key), value, ttl)
+}
+
+func (s *namespacedStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.Store.SetNX(ctx, s.key(key), value, ttl)
+}
+
+func (s *namespacedStore) Delete(ctx context.Context, keys ...string) error {
+	return s.Store.Delete(ctx, s.keys(keys)...)
+}
+
+func (s *namespacedStore) DeleteIfEquals(ctx context.Context, key string, value []byte) (bool, error) {
+	return s.Store.DeleteIfEquals(ctx, s.key(key), value)
+}
+
+func (s *namespacedStore) ExpireIfEquals(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.Store.ExpireIfEquals(ctx, s.key(key), value, ttl)
+}
+
+func (s *namespacedStore) SetTagged(ctx context.Context, key string, value []byte, ttl time.Duration, tagKeys ...string) error {
+	return s.Store.SetTagged(ctx, s.key(key), value, ttl, s.keys(tagKeys)...)
+}
+
+func (s *namespacedStore) DeleteTagged(ctx context.Context, tagKey string) ([]string, error) {
+	keys, err := s.Store.DeleteTagged(ctx, s.key(tagKey))
+	return s.strip(keys), err
+}
+
+func (s *namespacedStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return s.Store.TTL(ctx, s.key(key))
+}
+
+func (s *namespacedStore) Incr(ctx context.Context, key string) (int64, error) {
+	return s.Store.Incr(ctx, s.key(key))
+}
+
+func (s *namespacedStore) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.Store.Expire(ctx, s.key(key), ttl)
+}
+
+func (s *namespacedStore) Scan(ctx context.Context, prefix string, fn func(keys []string) error) error {
+	return s.Store.Scan(ctx, s.key(prefix), func(keys []string) error {
+		return fn(s.strip(keys))
+	})
+}
+
+func (s *namespacedStore) Size(ctx context.Context, keys ...string) ([]int64, error) {
+	return s.Store.Size(ctx, s.keys(keys)...)
+}
+
+func (s *namespacedStore) Pipeline() Pipeline {
+	return &namespacedPipeline{Pipeline: s.Store.Pipeline(), store: s}
+}
+
+type namespacedPipeline struct {
+	Pipeline
+	store *namespacedStore
+}
+
+func (p *namespacedPipeline) Set(key string, value []byte, ttl time.Duration) {
+	p.Pipeline.Set(p.store.key(key), value, ttl)
+}
+
+func (p *namespacedPipeline) Delete(keys ...string) {
+	p.Pipeline.Delete(p.store.keys(keys)...)
+}
+
+func (p *namespacedPipeline) Incr(key string) *Result[int64] {
+	return p.Pipeline.Incr(p.store.key(key))
+}
+
+func (p *namespacedPipeline) Expire(key string, ttl time.Duration) *Result[bool] {
+	return p.Pipeline.Expire(p.store.key(key), ttl)
+}
+
+func (p *namespacedPipeline) TTL(key string) *Result[time.Duration] {
+	return p.Pipeline.TTL(p.store.key(key))
+}
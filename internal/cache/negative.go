@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned for keys cached as not found. It wraps
+// gorm.ErrRecordNotFound, so callers handle it like the database's own miss.
+var ErrNotFound = fmt.Errorf("cached as not found: %w", gorm.ErrRecordNotFound)
+
+// notFoundValue marks a key whose resource does not exist. It carries the
+// encoding header with codec zero, which no codec uses.
+var notFoundValue = []byte{encodingVersion, 0, compressionNone}
+
+func isNotFoundValue(data []byte) bool {
+	return bytes.Equal(data, notFoundValue)
+}
+
+// SetNotFound remembers for CACHE_NEGATIVE_TTL_SECONDS that key's resource
+// does not exist, so repeated lookups of missing IDs stay off the database.
+// Tags work as in SetWithTTL: invalidating any of them clears the entry.
+func SetNotFound(key string, tags ...string) error {
+	ttl := config.AppConfig.Cache.NegativeTTL
+	if ttl <= 0 {
+		return nil
+	}
+
+	metrics.RecordCacheOperation("set", "not_found")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("set", time.Since(startTime))
+	}()
+
+	var err error
+	if len(tags) > 0 {
+		err = Backend.SetTagged(ctx, key, notFoundValue, ttl, tagKeys(tags)...)
+	} else {
+		err = Backend.Set(ctx, key, notFoundValue, ttl)
+	}
+	if err != nil {
+		logger.Warn().Err(err).Str("key", key).Msg("Failed to cache not found result")
+		return err
+	}
+	invalidate(key)
+
+	logger.Debug().Str("key", key).Dur("ttl", ttl).Msg("Cached not found result")
+	return nil
+}
+
+// ClearNotFound drops the cached copies of newly created records, which may
+// still be cached as not found
+func ClearNotFound(records ...interface{}) {
+	if Backend == nil {
+		return
+	}
+
+	var keys []string
+	for _, record := range records {
+		value := reflect.Indirect(reflect.ValueOf(record))
+		if !value.IsValid() {
+			continue
+		}
+		switch record := value.Interface().(type) {
+		case models.DummyProduct:
+			keys = append(keys, DummyProductKey(record.ID))
+		case models.User:
+			keys = append(keys, userKey(record.ID))
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	metrics.RecordCacheOperation("delete", "not_found")
+	if err := Backend.Delete(ctx, keys...); err != nil {
+		logger.Warn().Err(err).Int("keys", len(keys)).Msg("Failed to clear not found results from cache")
+		return
+	}
+	invalidate(keys...)
+}
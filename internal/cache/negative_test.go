@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"errors"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestNegativeCaching(t *testing.T) {
+	previous, previousTTL := Backend, config.AppConfig.Cache.NegativeTTL
+	Backend = NewMemoryStore()
+	config.AppConfig.Cache.NegativeTTL = time.Minute
+	defer func() { Backend, config.AppConfig.Cache.NegativeTTL = previous, previousTTL }()
+
+	loads := 0
+	load := func() (models.DummyProduct, error) {
+		loads++
+		return models.DummyProduct{}, gorm.ErrRecordNotFound
+	}
+
+	key := DummyProductKey(42)
+	for i := 0; i < 3; i++ {
+		if _, err := GetOrLoad(key, time.Minute, load, DummyProductTag(42)); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("lookup %d: err = %v, want gorm.ErrRecordNotFound", i, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loader called %d times, want 1", loads)
+	}
+
+	var product models.DummyProduct
+	if found, err := Get(key, &product); found || !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get = %v, %v, want ErrNotFound", found, err)
+	}
+
+	ClearNotFound(&models.DummyProduct{ID: 42})
+	GetOrLoad(key, time.Minute, load, DummyProductTag(42))
+	if loads != 2 {
+		t.Errorf("expected a lookup after the product was created to reach the loader, got %d loads", loads)
+	}
+
+	InvalidateDummyProducts([]uint{42})
+	GetOrLoad(key, time.Minute, load, DummyProductTag(42))
+	if loads != 3 {
+		t.Errorf("expected invalidating the product's tag to clear its not found result, got %d loads", loads)
+	}
+}
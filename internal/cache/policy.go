@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"fmt"
+	"goapi-starter/internal/config"
+)
+
+// FailurePolicy decides what a caller does when the cache cannot answer
+type FailurePolicy string
+
+const (
+	// FailOpen carries on as if the cache held nothing: tokens are accepted,
+	// requests are let through and values are loaded from the database
+	FailOpen FailurePolicy = "open"
+	// FailClosed refuses the operation rather than act without the cache
+	FailClosed FailurePolicy = "closed"
+)
+
+// ParseFailurePolicy validates a CACHE_*_FAILURE_POLICY value
+func ParseFailurePolicy(value string) (FailurePolicy, error) {
+	switch policy := FailurePolicy(value); policy {
+	case FailOpen, FailClosed:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown cache failure policy %q, use open or closed", value)
+}
+
+// BlacklistPolicy applies to tokens the local blacklist filter does not know
+func BlacklistPolicy() FailurePolicy {
+	return FailurePolicy(config.AppConfig.Cache.BlacklistFailurePolicy)
+}
+
+// RateLimitPolicy applies to rate limit checks
+func RateLimitPolicy() FailurePolicy {
+	return FailurePolicy(config.AppConfig.Cache.RateLimitFailurePolicy)
+}
+
+// DataPolicy applies to cache misses in GetOrLoad
+func DataPolicy() FailurePolicy {
+	return FailurePolicy(config.AppConfig.Cache.DataFailurePolicy)
+}
@@ -0,0 +1,401 @@
+package cache
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// RedisClient is the client behind the Redis backend, nil for other backends
+	RedisClient redis.UniversalClient
+	ctx         = context.Background()
+)
+
+// scanBatchSize is the COUNT hint passed to SCAN
+const scanBatchSize = 500
+
+// globEscaper escapes the characters SCAN MATCH treats as a pattern
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// deleteIfEqualsScript deletes a key only if it still holds the given value
+var deleteIfEqualsScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// expireIfEqualsScript sets the TTL of a key only if it still holds the given value
+var expireIfEqualsScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// setTaggedScript stores a value and adds its key to tag sets, extending each
+// set's TTL so it never expires before a key it lists
+var setTaggedScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call("EXISTS", KEYS[i]) == 1
+	local current = redis.call("PTTL", KEYS[i])
+	redis.call("SADD", KEYS[i], KEYS[1])
+	if ttl == 0 then
+		redis.call("PERSIST", KEYS[i])
+	elseif not existed or (current >= 0 and current < ttl) then
+		redis.call("PEXPIRE", KEYS[i], ttl)
+	end
+end
+return 1`)
+
+// addTagScript adds a key to one tag set, extending the set's TTL like
+// setTaggedScript. Cluster mode uses it per tag, since the keys of one
+// SetTagged call usually live in different slots.
+var addTagScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+local existed = redis.call("EXISTS", KEYS[1]) == 1
+local current = redis.call("PTTL", KEYS[1])
+redis.call("SADD", KEYS[1], ARGV[1])
+if ttl == 0 then
+	redis.call("PERSIST", KEYS[1])
+elseif not existed or (current >= 0 and current < ttl) then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+end
+return 1`)
+
+// popTagScript removes a tag set and returns the keys it listed. Cluster mode
+// then deletes the keys separately, as they live in other slots.
+var popTagScript = redis.NewScript(`
+local keys = redis.call("SMEMBERS", KEYS[1])
+redis.call("DEL", KEYS[1])
+return keys`)
+
+// deleteTaggedScript deletes a tag set and every key it lists
+var deleteTaggedScript = redis.NewScript(`
+local keys = redis.call("SMEMBERS", KEYS[1])
+for i = 1, #keys, 500 do
+	redis.call("DEL", unpack(keys, i, math.min(i + 499, #keys)))
+end
+redis.call("DEL", KEYS[1])
+return keys`)
+
+// RedisStore is a Store backed by a standalone, Sentinel or Cluster Redis
+type RedisStore struct {
+	client  redis.UniversalClient
+	cluster bool
+}
+
+// NewRedisStore wraps a Redis client. Cluster clients get cluster-safe
+// variants of the commands spanning several keys.
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	_, cluster := client.(*redis.ClusterClient)
+	return &RedisStore{client: client, cluster: cluster}
+}
+
+// initRedis connects to Redis and returns the ping error if the server is
+// unreachable. That is not fatal: the circuit breaker starts open and
+// reconnects in the background. A configuration that cannot work, such as an
+// unreadable CA file, is fatal.
+func initRedis() (*RedisStore, error) {
+	logger.Info().Msg("Initializing Redis connection")
+
+	redisConfig := config.AppConfig.Redis
+	client, err := newRedisClient(redisConfig)
+	if err != nil {
+		logger.Fatal().Err(err).Str("mode", redisConfig.Mode).Msg("Invalid Redis configuration")
+	}
+	RedisClient = client
+
+	// Test the connection
+	if err := RedisClient.Ping(ctx).Err(); err != nil {
+		logger.Error().
+			Err(err).
+			Str("mode", redisConfig.Mode).
+			Strs("addrs", redisConfig.RedisAddrs()).
+			Msg("Failed to connect to Redis, continuing without cache until it is reachable")
+		return NewRedisStore(RedisClient), err
+	}
+
+	logger.Info().
+		Str("mode", redisConfig.Mode).
+		Strs("addrs", redisConfig.RedisAddrs()).
+		Msg("Successfully connected to Redis")
+	return NewRedisStore(RedisClient), nil
+}
+
+// newRedisClient builds the client for REDIS_MODE
+func newRedisClient(cfg config.RedisConfig) (redis.UniversalClient, error) {
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.RedisAddrs(),
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		DB:               cfg.DB,
+		MasterName:       cfg.SentinelMaster,
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		PoolTimeout:      cfg.PoolTimeout,
+		MaxRetries:       cfg.MaxRetries,
+		MinRetryBackoff:  cfg.MinRetryBackoff,
+		MaxRetryBackoff:  cfg.MaxRetryBackoff,
+	}
+
+	if cfg.TLSEnabled {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			ServerName:         cfg.TLSServerName,
+			InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+		}
+		if cfg.TLSCAFile != "" {
+			pem, err := os.ReadFile(cfg.TLSCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading Redis CA file: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in Redis CA file %s", cfg.TLSCAFile)
+			}
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	switch cfg.Mode {
+	case "standalone":
+		return redis.NewClient(opts.Simple()), nil
+	case "sentinel":
+		if cfg.SentinelMaster == "" {
+			return nil, errors.New("REDIS_SENTINEL_MASTER is required in sentinel mode")
+		}
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case "cluster":
+		return redis.NewClusterClient(opts.Cluster()), nil
+	}
+	return nil, fmt.Errorf("unknown Redis mode %q", cfg.Mode)
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *RedisStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if !s.cluster {
+		return s.client.Del(ctx, keys...).Err()
+	}
+
+	// A multi-key DEL fails across slots, so delete the keys one by one in a
+	// pipeline, which the cluster client splits by node
+	pipe := s.client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisStore) DeleteIfEquals(ctx context.Context, key string, value []byte) (bool, error) {
+	deleted, err := deleteIfEqualsScript.Run(ctx, s.client, []string{key}, value).Int()
+	return deleted == 1, err
+}
+
+func (s *RedisStore) ExpireIfEquals(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	expired, err := expireIfEqualsScript.Run(ctx, s.client, []string{key}, value, ttl.Milliseconds()).Int()
+	return expired == 1, err
+}
+
+func (s *RedisStore) SetTagged(ctx context.Context, key string, value []byte, ttl time.Duration, tagKeys ...string) error {
+	if !s.cluster {
+		keys := append([]string{key}, tagKeys...)
+		return setTaggedScript.Run(ctx, s.client, keys, value, ttl.Milliseconds()).Err()
+	}
+
+	// Tag the key before storing it, so an invalidation running in between
+	// can only drop the value, never leave it untagged
+	for _, tagKey := range tagKeys {
+		if err := addTagScript.Run(ctx, s.client, []string{tagKey}, key, ttl.Milliseconds()).Err(); err != nil {
+			return err
+		}
+	}
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *RedisStore) DeleteTagged(ctx context.Context, tagKey string) ([]string, error) {
+	if !s.cluster {
+		return deleteTaggedScript.Run(ctx, s.client, []string{tagKey}).StringSlice()
+	}
+
+	keys, err := popTagScript.Run(ctx, s.client, []string{tagKey}).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	return keys, s.Delete(ctx, keys...)
+}
+
+func (s *RedisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return redisTTL(s.client.TTL(ctx, key))
+}
+
+func (s *RedisStore) Incr(ctx context.Context, key string) (int64, error) {
+	return s.client.Incr(ctx, key).Result()
+}
+
+func (s *RedisStore) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.client.Expire(ctx, key, ttl).Result()
+}
+
+func (s *RedisStore) Scan(ctx context.Context, prefix string, fn func(keys []string) error) error {
+	match := globEscaper.Replace(prefix) + "*"
+	cluster, ok := s.client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, s.client, match, fn)
+	}
+
+	// Each master holds a share of the keys; fn is called from one node at a time
+	var mu sync.Mutex
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return scanNode(ctx, node, match, func(keys []string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return fn(keys)
+		})
+	})
+}
+
+// scanNode runs SCAN to completion on a single node
+func scanNode(ctx context.Context, client redis.Cmdable, match string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, match, scanBatchSize).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if cursor = next; cursor == 0 {
+			return nil
+		}
+	}
+}
+
+func (s *RedisStore) Size(ctx context.Context, keys ...string) ([]int64, error) {
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.MemoryUsage(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	sizes := make([]int64, len(keys))
+	for i, cmd := range cmds {
+		sizes[i] = cmd.Val()
+	}
+	return sizes, nil
+}
+
+func (s *RedisStore) Pipeline() Pipeline {
+	return &redisPipeline{pipe: s.client.Pipeline()}
+}
+
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// redisTTL maps Redis' -1 and -2 replies onto TTLNoExpiry and TTLMissing
+func redisTTL(cmd *redis.DurationCmd) (time.Duration, error) {
+	ttl, err := cmd.Result()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case ttl == -1 || ttl == -time.Second:
+		return TTLNoExpiry, nil
+	case ttl == -2 || ttl == -2*time.Second:
+		return TTLMissing, nil
+	}
+	return ttl, nil
+}
+
+// redisPipeline copies go-redis command results into Results after Exec
+type redisPipeline struct {
+	pipe    redis.Pipeliner
+	resolve []func()
+}
+
+func (p *redisPipeline) Set(key string, value []byte, ttl time.Duration) {
+	p.pipe.Set(ctx, key, value, ttl)
+}
+
+func (p *redisPipeline) Delete(keys ...string) {
+	p.pipe.Del(ctx, keys...)
+}
+
+func (p *redisPipeline) Incr(key string) *Result[int64] {
+	cmd, result := p.pipe.Incr(ctx, key), &Result[int64]{}
+	p.resolve = append(p.resolve, func() { result.val, result.err = cmd.Result() })
+	return result
+}
+
+func (p *redisPipeline) Expire(key string, ttl time.Duration) *Result[bool] {
+	cmd, result := p.pipe.Expire(ctx, key, ttl), &Result[bool]{}
+	p.resolve = append(p.resolve, func() { result.val, result.err = cmd.Result() })
+	return result
+}
+
+func (p *redisPipeline) TTL(key string) *Result[time.Duration] {
+	cmd, result := p.pipe.TTL(ctx, key), &Result[time.Duration]{}
+	p.resolve = append(p.resolve, func() { result.val, result.err = redisTTL(cmd) })
+	return result
+}
+
+func (p *redisPipeline) Exec(ctx context.Context) error {
+	_, err := p.pipe.Exec(ctx)
+	for _, resolve := range p.resolve {
+		resolve()
+	}
+	return err
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"goapi-starter/internal/config"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNewRedisClientModes(t *testing.T) {
+	cfg := config.RedisConfig{Host: "localhost", Port: "6379", SentinelMaster: "mymaster"}
+
+	for mode, cluster := range map[string]bool{"standalone": false, "sentinel": false, "cluster": true} {
+		cfg.Mode = mode
+		client, err := newRedisClient(cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if store := NewRedisStore(client); store.cluster != cluster {
+			t.Errorf("%s: cluster = %v", mode, store.cluster)
+		}
+		if _, isCluster := client.(*redis.ClusterClient); isCluster != cluster {
+			t.Errorf("%s: got %T", mode, client)
+		}
+		client.Close()
+	}
+
+	cfg.Mode = "replicated"
+	if _, err := newRedisClient(cfg); err == nil {
+		t.Error("expected an unknown mode to be rejected")
+	}
+
+	cfg.Mode, cfg.TLSEnabled, cfg.TLSCAFile = "standalone", true, "/nonexistent/ca.pem"
+	if _, err := newRedisClient(cfg); err == nil {
+		t.Error("expected a missing CA file to be rejected")
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/models"
+	"strings"
+	"time"
+)
+
+const (
+	// SearchCachePrefix is the prefix for cached product search results
+	SearchCachePrefix = "search:dummy_products"
+	// SearchCacheTTL is how long to cache search results
+	SearchCacheTTL = 5 * time.Minute
+)
+
+// searchCacheKey derives the cache key from the normalized search parameters
+func searchCacheKey(query string, limit int) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", normalized, limit)))
+	return fmt.Sprintf("%s:%s", SearchCachePrefix, hex.EncodeToString(sum[:16]))
+}
+
+// CacheSearchResults stores product search results in the cache until they
+// expire or any product changes
+func CacheSearchResults(query string, limit int, response models.DummyProductSearchResponse) error {
+	key := searchCacheKey(query, limit)
+	if err := SetWithTTL(key, response, SearchCacheTTL, DummyProductsTag); err != nil {
+		logger.Warn().Err(err).Str("query", query).Msg("Failed to cache search results")
+		return err
+	}
+	return nil
+}
+
+// GetCachedSearchResults retrieves product search results from the cache
+func GetCachedSearchResults(query string, limit int) (*models.DummyProductSearchResponse, bool, error) {
+	key := searchCacheKey(query, limit)
+	var response models.DummyProductSearchResponse
+
+	found, err := Get(key, &response)
+	if err != nil {
+		logger.Warn().Err(err).Str("query", query).Msg("Error retrieving search results from cache")
+		return nil, false, err
+	}
+
+	if !found {
+		return nil, false, nil
+	}
+
+	return &response, true, nil
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"time"
+)
+
+const (
+	// TTLMissing is the TTL reported for keys that do not exist
+	TTLMissing time.Duration = -2
+	// TTLNoExpiry is the TTL reported for keys that never expire
+	TTLNoExpiry time.Duration = -1
+)
+
+// Store is a key-value backend with expiring keys. Values are opaque bytes; a
+// ttl of zero stores a key without expiry.
+type Store interface {
+	// Get returns the value of key; found is false when the key does not exist
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// Set stores value under key, replacing any existing value and TTL
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value only if key does not exist and reports whether it did
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// Delete removes keys; deleting a missing key is not an error
+	Delete(ctx context.Context, keys ...string) error
+	// DeleteIfEquals removes key only while it still holds value, so a lock is
+	// never released by anyone but its holder
+	DeleteIfEquals(ctx context.Context, key string, value []byte) (bool, error)
+	// ExpireIfEquals sets the TTL of key only while it still holds value, so
+	// a lock's lease is only ever extended by its holder
+	ExpireIfEquals(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// SetTagged stores value like Set and adds key to each of the tag sets,
+	// whose TTL is extended to outlive it
+	SetTagged(ctx context.Context, key string, value []byte, ttl time.Duration, tagKeys ...string) error
+	// DeleteTagged atomically removes the tag set and every key in it,
+	// returning the keys that were listed
+	DeleteTagged(ctx context.Context, tagKey string) ([]string, error)
+	// TTL returns the remaining time to live of key, or TTLMissing or TTLNoExpiry
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Incr atomically increments the integer value of key, starting from zero,
+	// and keeps its TTL
+	Incr(ctx context.Context, key string) (int64, error)
+	// Expire sets the TTL of an existing key and reports whether the key exists
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Scan calls fn with batches of the keys starting with prefix. Keys added
+	// or removed during the scan may or may not be seen.
+	Scan(ctx context.Context, prefix string, fn func(keys []string) error) error
+	// Size returns the approximate memory used by each key, zero for missing keys
+	Size(ctx context.Context, keys ...string) ([]int64, error)
+	// Pipeline queues commands that are sent together by Exec
+	Pipeline() Pipeline
+	// Ping checks that the backend is reachable
+	Ping(ctx context.Context) error
+	// Close releases the backend's resources
+	Close() error
+}
+
+// Pipeline batches commands into a single round trip. Results are available
+// once Exec returns.
+type Pipeline interface {
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(keys ...string)
+	Incr(key string) *Result[int64]
+	Expire(key string, ttl time.Duration) *Result[bool]
+	TTL(key string) *Result[time.Duration]
+	// Exec sends the queued commands and returns the first error among them
+	Exec(ctx context.Context) error
+}
+
+// Result holds the outcome of a pipelined command
+type Result[T any] struct {
+	val T
+	err error
+}
+
+// Val returns the command's value, the zero value until Exec has run
+func (r *Result[T]) Val() T { return r.val }
+
+// Err returns the command's error
+func (r *Result[T]) Err() error { return r.err }
+
+var (
+	// Backend is the store used by the application
+	Backend Store
+	// backendName is the CACHE_BACKEND in use, reported by Health
+	backendName string
+)
+
+// InitStore creates the store selected by CACHE_BACKEND, with its keys under
+// CACHE_NAMESPACE. Redis is wrapped in a circuit breaker, so an outage makes
+// calls fail fast with ErrCacheUnavailable until the server is back.
+func InitStore() {
+	cfg := config.AppConfig.Cache
+
+	if codec, err := CodecByName(cfg.Codec); err != nil || codec == ProtobufCodec {
+		logger.Fatal().Str("codec", cfg.Codec).Msg("Unsupported cache codec, use json or msgpack")
+	}
+	for _, policy := range []string{cfg.BlacklistFailurePolicy, cfg.RateLimitFailurePolicy, cfg.DataFailurePolicy} {
+		if _, err := ParseFailurePolicy(policy); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to initialize cache")
+		}
+	}
+
+	var store Store
+	switch cfg.Backend {
+	case "redis":
+		redisStore, err := initRedis()
+		guarded := newBreakerStore(redisStore, cfg.BreakerFailureThreshold, cfg.BreakerRetryInterval)
+		if err != nil {
+			guarded.breaker.trip(err)
+		}
+		storeBreaker = guarded.breaker
+		store = guarded
+	case "memory":
+		store = NewMemoryStore()
+	default:
+		logger.Fatal().Err(fmt.Errorf("unknown cache backend %q", cfg.Backend)).Msg("Failed to initialize cache")
+	}
+	Backend = WithNamespace(store, cfg.Namespace)
+	backendName = cfg.Backend
+
+	if cfg.Backend == "redis" {
+		initLocalCache()
+		if err := migrateLegacyBlacklist(); err != nil {
+			// Retried once the cache is reachable again
+			storeBreaker.whenRecovered(func() { migrateLegacyBlacklist() })
+		}
+		initLocalBlacklist(storeBreaker)
+	}
+
+	logger.Info().Str("backend", cfg.Backend).Str("namespace", cfg.Namespace).Msg("Cache initialized")
+}
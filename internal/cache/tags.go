@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"time"
+)
+
+// TagPrefix prefixes the sets listing the keys stored under a tag
+const TagPrefix = "tag:"
+
+// tagKeys maps tags such as "products" or "product:42" onto their set keys
+func tagKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = TagPrefix + tag
+	}
+	return keys
+}
+
+// InvalidateTag removes every key stored with the tag
+func InvalidateTag(tag string) error {
+	return InvalidateTags(tag)
+}
+
+// InvalidateTags removes every key stored with any of the tags. Each tag is
+// removed atomically, so a key is never left behind once its tag is gone.
+func InvalidateTags(tags ...string) error {
+	metrics.RecordCacheOperation("invalidate", "tag")
+	startTime := time.Now()
+	defer func() {
+		metrics.RecordCacheDuration("invalidate", time.Since(startTime))
+	}()
+
+	var first error
+	for _, tag := range tags {
+		keys, err := Backend.DeleteTagged(ctx, TagPrefix+tag)
+		if err != nil {
+			logger.Error().Err(err).Str("tag", tag).Msg("Failed to invalidate cache tag")
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		invalidate(keys...)
+
+		logger.Debug().Str("tag", tag).Int("keys", len(keys)).Msg("Successfully invalidated cache tag")
+	}
+	return first
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"goapi-starter/internal/config"
 	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,7 +18,7 @@ const (
 // BlacklistToken adds a token to the blacklist
 // The TTL should match the token's remaining validity period
 func BlacklistToken(tokenType string, token string, ttl time.Duration) error {
-	key := fmt.Sprintf("%s:%s:%s", TokenBlacklistPrefix, tokenType, token)
+	key := blacklistKey(tokenType, token)
 
 	// Store a simple value (timestamp when it was blacklisted)
 	now := time.Now().Unix()
@@ -26,28 +29,59 @@ func BlacklistToken(tokenType string, token string, ttl time.Duration) error {
 		Dur("ttl", ttl).
 		Msg("Blacklisting token")
 
-	return SetWithTTL(key, now, ttl)
+	if blacklistFilter != nil {
+		blacklistFilter.add(key)
+	}
+
+	if err := SetWithTTL(key, now, ttl); err != nil {
+		if blacklistFilter == nil {
+			return err
+		}
+		// Enforce the revocation locally and store it once the cache recovers
+		blacklistFilter.keep(key, now, time.Now().Add(ttl))
+		logger.Warn().
+			Err(err).
+			Str("token_type", tokenType).
+			Msg("Failed to store blacklisted token, keeping it in the local blacklist")
+	}
+	return nil
 }
 
-// IsTokenBlacklisted checks if a token is in the blacklist
+// IsTokenBlacklisted checks if a token is in the blacklist. When the cache
+// cannot be read, tokens in the local blacklist are reported as revoked and
+// the others are accepted or rejected according to
+// CACHE_BLACKLIST_FAILURE_POLICY.
 func IsTokenBlacklisted(tokenType string, token string) (bool, error) {
-	key := fmt.Sprintf("%s:%s:%s", TokenBlacklistPrefix, tokenType, token)
+	key := blacklistKey(tokenType, token)
 
 	var timestamp int64
 	found, err := Get(key, &timestamp)
-
 	if err != nil {
 		logger.Warn().
 			Err(err).
 			Str("token_type", tokenType).
 			Str("token", token[:10]+"...").
 			Msg("Error checking token blacklist")
-		return false, err
+		return checkLocalBlacklist(err, key)
 	}
 
 	return found, nil
 }
 
+// checkLocalBlacklist answers a blacklist check the cache failed
+func checkLocalBlacklist(err error, keys ...string) (bool, error) {
+	if blacklistFilter != nil && blacklistFilter.contains(keys...) {
+		metrics.RecordCacheDegradedDecision("blacklist", "revoked")
+		return true, nil
+	}
+	if BlacklistPolicy() == FailClosed {
+		metrics.RecordCacheDegradedDecision("blacklist", "rejected")
+		return false, err
+	}
+	metrics.RecordCacheDegradedDecision("blacklist", "allowed")
+	return false, nil
+}
+
 // BlacklistAccessToken adds an access token to the blacklist
 func BlacklistAccessToken(token string) error {
 	// Use the configured access token expiry or a default
@@ -79,3 +113,69 @@ func IsAccessTokenBlacklisted(token string) (bool, error) {
 func IsRefreshTokenBlacklisted(token string) (bool, error) {
 	return IsTokenBlacklisted("refresh", token)
 }
+
+// blacklistKey returns the key of a blacklisted token. The token is a hash
+// tag, so in Redis Cluster its access and refresh entries share a slot.
+func blacklistKey(tokenType string, token string) string {
+	return fmt.Sprintf("%s:%s:{%s}", TokenBlacklistPrefix, tokenType, token)
+}
+
+// legacyBlacklistMigrated is set once migrateLegacyBlacklist has completed
+var legacyBlacklistMigrated atomic.Bool
+
+// migrateLegacyBlacklist moves tokens revoked under the key format used before
+// hash tags, blacklist:type:token, to their current keys with the TTL they had
+// left, so checks only need to read one key. Every instance runs it at
+// startup, which also picks up tokens revoked by instances still running an
+// older version during a rolling deploy.
+func migrateLegacyBlacklist() error {
+	if legacyBlacklistMigrated.Load() {
+		return nil
+	}
+
+	moved := 0
+	err := Backend.Scan(ctx, TokenBlacklistPrefix+":", func(keys []string) error {
+		for _, key := range keys {
+			tokenType, token, ok := strings.Cut(strings.TrimPrefix(key, TokenBlacklistPrefix+":"), ":")
+			if !ok || strings.HasPrefix(token, "{") {
+				continue
+			}
+
+			value, exists, err := Backend.Get(ctx, key)
+			if err != nil {
+				return err
+			}
+			ttl, err := Backend.TTL(ctx, key)
+			if err != nil {
+				return err
+			}
+			if !exists || ttl == TTLMissing {
+				continue
+			}
+			if ttl == TTLNoExpiry {
+				ttl = 0
+			}
+
+			migrated := blacklistKey(tokenType, token)
+			if err := Backend.Set(ctx, migrated, value, ttl); err != nil {
+				return err
+			}
+			invalidate(migrated)
+			if err := Backend.Delete(ctx, key); err != nil {
+				return err
+			}
+			moved++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Warn().Err(err).Int("moved", moved).Msg("Failed to migrate legacy blacklist keys")
+		return err
+	}
+
+	legacyBlacklistMigrated.Store(true)
+	if moved > 0 {
+		logger.Info().Int("tokens", moved).Msg("Migrated legacy blacklist keys")
+	}
+	return nil
+}
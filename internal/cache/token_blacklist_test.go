@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMigrateLegacyBlacklist(t *testing.T) {
+	previous := Backend
+	Backend = NewMemoryStore()
+	defer func() {
+		Backend = previous
+		legacyBlacklistMigrated.Store(false)
+	}()
+
+	token := "legacy-revoked-token"
+	legacyKey := TokenBlacklistPrefix + ":refresh:" + token
+	if err := SetWithTTL(legacyKey, int64(1), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := BlacklistToken("access", "current-revoked-token", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, _ := IsTokenBlacklisted("refresh", token); revoked {
+		t.Fatal("expected legacy keys to no longer be read directly")
+	}
+	if err := migrateLegacyBlacklist(); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, err := IsTokenBlacklisted("refresh", token); err != nil || !revoked {
+		t.Errorf("migrated token revoked = %v, %v, want true", revoked, err)
+	}
+	if ttl, _ := GetTTL(blacklistKey("refresh", token)); ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("migrated TTL = %v, want the remaining hour", ttl)
+	}
+	if ttl, _ := GetTTL(legacyKey); ttl != TTLMissing {
+		t.Errorf("legacy key TTL = %v, want it deleted", ttl)
+	}
+	if revoked, _ := IsTokenBlacklisted("access", "current-revoked-token"); !revoked {
+		t.Error("expected current keys to be left alone")
+	}
+}
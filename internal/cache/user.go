@@ -1,8 +1,10 @@
 package cache
 
 import (
+	"encoding/json"
 	"fmt"
 	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
 	"goapi-starter/internal/models"
 	"time"
 )
@@ -20,30 +22,51 @@ func CacheUser(user models.User) error {
 	userToCache := user
 	userToCache.Password = ""
 
-	key := fmt.Sprintf("%s:%s", UserCachePrefix, user.ID)
-	return SetWithTTL(key, userToCache, UserCacheTTL)
+	return Prime(userKey(user.ID), userToCache, UserCacheTTL)
 }
 
-// GetCachedUser retrieves a user from the cache
+// GetCachedUser retrieves a user from the cache without loading it on a miss
 func GetCachedUser(userID string) (*models.User, bool, error) {
-	key := fmt.Sprintf("%s:%s", UserCachePrefix, userID)
-	var user models.User
-
-	found, err := Get(key, &user)
-	if err != nil {
-		logger.Warn().Err(err).Str("user_id", userID).Msg("Error retrieving user from cache")
-		return nil, false, err
+	entry, found, notFound := getEntry(userKey(userID))
+	if notFound {
+		metrics.RecordCacheResult("negative_hit")
+		return nil, false, nil
 	}
-
 	if !found {
+		metrics.RecordCacheResult("miss")
 		return nil, false, nil
 	}
 
+	var user models.User
+	if err := json.Unmarshal(entry.Value, &user); err != nil {
+		logger.Warn().Err(err).Str("user_id", userID).Msg("Error decoding user from cache")
+		metrics.RecordCacheResult("unmarshal_error")
+		return nil, false, err
+	}
+
+	metrics.RecordCacheResult("hit")
 	return &user, true, nil
 }
 
+// GetOrLoadUser returns a user from the cache, loading and caching it on a
+// miss. The password is never cached.
+func GetOrLoadUser(userID string, loader func() (models.User, error)) (*models.User, error) {
+	user, err := GetOrLoad(userKey(userID), UserCacheTTL, func() (models.User, error) {
+		user, err := loader()
+		user.Password = ""
+		return user, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // InvalidateUserCache removes a user from the cache
 func InvalidateUserCache(userID string) error {
-	key := fmt.Sprintf("%s:%s", UserCachePrefix, userID)
-	return Delete(key)
+	return Delete(userKey(userID))
+}
+
+func userKey(userID string) string {
+	return fmt.Sprintf("%s:%s", UserCachePrefix, userID)
 }
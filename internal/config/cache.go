@@ -0,0 +1,86 @@
+package config
+
+import (
+	"goapi-starter/internal/logger"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CacheConfig struct {
+	Backend              string        // Store holding cached values: redis or memory
+	Namespace            string        // Prefix of every key, such as goapi:production, so apps and environments can share a Redis
+	Codec                string        // Serialization of cached values: json or msgpack; protobuf messages always use protobuf
+	CompressionThreshold int           // Encoded values of at least this many bytes are compressed; 0 disables compression
+	L1Enabled            bool          // Keep an in-process cache in front of Redis
+	L1MaxEntries         int           // Entries kept before the least recently used are evicted
+	L1TTL                time.Duration // Longest time an entry, or a miss, is served from the L1 cache
+	L1Prefixes           []string      // Key prefixes cached in the L1; everything else always goes to Redis
+	NegativeTTL          time.Duration // How long a lookup that found nothing is remembered; 0 disables negative caching
+
+	BreakerFailureThreshold int           // Consecutive backend failures that open the circuit breaker
+	BreakerRetryInterval    time.Duration // How often the backend is pinged while the breaker is open
+	BlacklistFailurePolicy  string        // open or closed: whether tokens unknown to the local blacklist are accepted while the cache is down
+	RateLimitFailurePolicy  string        // open or closed: whether requests are let through while the cache is down
+	DataFailurePolicy       string        // open or closed: whether cache misses load from the database while the cache is down
+	BlacklistSyncInterval   time.Duration // How often the local blacklist filter is rebuilt from the cache
+	BlacklistFilterSize     int           // Revoked tokens the local blacklist filter is sized for
+	LeaderLeaseTTL          time.Duration // Lease of leader elections, such as the one picking the instance that runs a background job
+}
+
+func loadCacheConfig() CacheConfig {
+	logger.Debug().Msg("Loading cache configuration")
+
+	l1Enabled, _ := strconv.ParseBool(getEnv("CACHE_L1_ENABLED", "false"))
+
+	config := CacheConfig{
+		Backend:              getEnv("CACHE_BACKEND", "redis"),
+		Namespace:            getEnv("CACHE_NAMESPACE", "goapi"),
+		Codec:                getEnv("CACHE_CODEC", "json"),
+		CompressionThreshold: getEnvAsInt("CACHE_COMPRESSION_THRESHOLD", 1024),
+		L1Enabled:            l1Enabled,
+		L1MaxEntries:         getEnvAsInt("CACHE_L1_MAX_ENTRIES", 10000),
+		L1TTL:                time.Duration(getEnvAsInt("CACHE_L1_TTL_SECONDS", 30)) * time.Second,
+		L1Prefixes:           splitList(getEnv("CACHE_L1_PREFIXES", "user,dummy_product,dummy_products")),
+		NegativeTTL:          time.Duration(getEnvAsInt("CACHE_NEGATIVE_TTL_SECONDS", 30)) * time.Second,
+
+		BreakerFailureThreshold: getEnvAsInt("CACHE_BREAKER_FAILURE_THRESHOLD", 5),
+		BreakerRetryInterval:    time.Duration(getEnvAsInt("CACHE_BREAKER_RETRY_INTERVAL_MS", 1000)) * time.Millisecond,
+		BlacklistFailurePolicy:  getEnv("CACHE_BLACKLIST_FAILURE_POLICY", "open"),
+		RateLimitFailurePolicy:  getEnv("CACHE_RATELIMIT_FAILURE_POLICY", "open"),
+		DataFailurePolicy:       getEnv("CACHE_DATA_FAILURE_POLICY", "open"),
+		BlacklistSyncInterval:   time.Duration(getEnvAsInt("CACHE_BLACKLIST_SYNC_INTERVAL_SECONDS", 60)) * time.Second,
+		BlacklistFilterSize:     getEnvAsInt("CACHE_BLACKLIST_FILTER_SIZE", 100000),
+		LeaderLeaseTTL:          time.Duration(getEnvAsInt("CACHE_LEADER_LEASE_SECONDS", 15)) * time.Second,
+	}
+
+	logger.Info().
+		Str("backend", config.Backend).
+		Str("namespace", config.Namespace).
+		Str("codec", config.Codec).
+		Int("compression_threshold", config.CompressionThreshold).
+		Bool("l1_enabled", config.L1Enabled).
+		Int("l1_max_entries", config.L1MaxEntries).
+		Dur("l1_ttl", config.L1TTL).
+		Strs("l1_prefixes", config.L1Prefixes).
+		Dur("negative_ttl", config.NegativeTTL).
+		Int("breaker_failure_threshold", config.BreakerFailureThreshold).
+		Dur("breaker_retry_interval", config.BreakerRetryInterval).
+		Str("blacklist_failure_policy", config.BlacklistFailurePolicy).
+		Str("ratelimit_failure_policy", config.RateLimitFailurePolicy).
+		Str("data_failure_policy", config.DataFailurePolicy).
+		Msg("Cache configuration loaded")
+
+	return config
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -13,6 +13,10 @@ type Config struct {
 	JWT      JWTConfig
 	Database DatabaseConfig
 	Redis    RedisConfig
+	Cache    CacheConfig
+	Products ProductsConfig
+	Currency CurrencyConfig
+	Storage  StorageConfig
 }
 
 type ServerConfig struct {
@@ -64,7 +68,11 @@ func LoadConfig() {
 			DBName:   getEnv("DB_NAME", "goapi_starter_db"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
-		Redis: loadRedisConfig(),
+		Redis:    loadRedisConfig(),
+		Cache:    loadCacheConfig(),
+		Products: loadProductsConfig(),
+		Currency: loadCurrencyConfig(),
+		Storage:  loadStorageConfig(),
 	}
 
 	// Log configuration (excluding sensitive data)
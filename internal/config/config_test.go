@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -39,6 +41,61 @@ func TestLoadConfig(t *testing.T) {
 		if AppConfig.Database.Port != "5432" {
 			t.Errorf("Expected default port to be 5432, got %s", AppConfig.Database.Port)
 		}
+
+		// Check Products defaults
+		if AppConfig.Products.TrashRetention != 720*time.Hour {
+			t.Errorf("Expected default trash retention to be 720h, got %s", AppConfig.Products.TrashRetention)
+		}
+
+		// Check Currency defaults
+		if AppConfig.Currency.Default != "USD" {
+			t.Errorf("Expected default currency to be USD, got %s", AppConfig.Currency.Default)
+		}
+		if len(AppConfig.Currency.Rates) != 0 {
+			t.Errorf("Expected no currency rates by default, got %d", len(AppConfig.Currency.Rates))
+		}
+
+		// Check Storage defaults
+		if AppConfig.Storage.Driver != "local" {
+			t.Errorf("Expected default storage driver to be local, got %s", AppConfig.Storage.Driver)
+		}
+		if !reflect.DeepEqual(AppConfig.Products.ThumbnailSizes, []int{128, 512}) {
+			t.Errorf("Expected default thumbnail sizes to be [128 512], got %v", AppConfig.Products.ThumbnailSizes)
+		}
+
+		// Check Redis connection defaults
+		if AppConfig.Redis.Mode != "standalone" || AppConfig.Redis.MaxRetries != 3 {
+			t.Errorf("Expected standalone Redis with 3 retries by default, got %s and %d", AppConfig.Redis.Mode, AppConfig.Redis.MaxRetries)
+		}
+		if addrs := AppConfig.Redis.RedisAddrs(); !reflect.DeepEqual(addrs, []string{"localhost:6379"}) {
+			t.Errorf("Expected Redis address to default to localhost:6379, got %v", addrs)
+		}
+
+		// Check Cache defaults
+		if AppConfig.Cache.Backend != "redis" {
+			t.Errorf("Expected default cache backend to be redis, got %s", AppConfig.Cache.Backend)
+		}
+		if AppConfig.Cache.Namespace != "goapi" {
+			t.Errorf("Expected default cache namespace to be goapi, got %s", AppConfig.Cache.Namespace)
+		}
+		if AppConfig.Cache.Codec != "json" || AppConfig.Cache.CompressionThreshold != 1024 {
+			t.Errorf("Expected json values compressed from 1024 bytes by default, got %s and %d", AppConfig.Cache.Codec, AppConfig.Cache.CompressionThreshold)
+		}
+		if AppConfig.Cache.L1Enabled {
+			t.Error("Expected the L1 cache to be disabled by default")
+		}
+		if !reflect.DeepEqual(AppConfig.Cache.L1Prefixes, []string{"user", "dummy_product", "dummy_products"}) {
+			t.Errorf("Unexpected default L1 prefixes %v", AppConfig.Cache.L1Prefixes)
+		}
+		if AppConfig.Cache.NegativeTTL != 30*time.Second {
+			t.Errorf("Expected not found results to be cached for 30s by default, got %v", AppConfig.Cache.NegativeTTL)
+		}
+		if AppConfig.Cache.LeaderLeaseTTL != 15*time.Second {
+			t.Errorf("Expected a 15s leader lease by default, got %v", AppConfig.Cache.LeaderLeaseTTL)
+		}
+		if AppConfig.Cache.BreakerFailureThreshold != 5 || AppConfig.Cache.BlacklistFailurePolicy != "open" {
+			t.Errorf("Expected a breaker opening after 5 failures and an open blacklist policy by default, got %d and %s", AppConfig.Cache.BreakerFailureThreshold, AppConfig.Cache.BlacklistFailurePolicy)
+		}
 	})
 
 	// Test custom environment values
@@ -119,3 +176,17 @@ func TestGetEnvAsInt(t *testing.T) {
 		}
 	})
 }
+
+func TestParseCurrencyRates(t *testing.T) {
+	rates := parseCurrencyRates("eur=0.92, GBP=0.79,JPY=149.5,XX=1,CHF=abc,SEK=-1")
+
+	if len(rates) != 3 {
+		t.Fatalf("Expected 3 valid rates, got %d", len(rates))
+	}
+	if rates["EUR"].RatString() != "23/25" {
+		t.Errorf("Expected EUR rate 23/25, got %s", rates["EUR"].RatString())
+	}
+	if rates["JPY"].RatString() != "299/2" {
+		t.Errorf("Expected JPY rate 299/2, got %s", rates["JPY"].RatString())
+	}
+}
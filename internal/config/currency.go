@@ -0,0 +1,57 @@
+package config
+
+import (
+	"goapi-starter/internal/logger"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+type CurrencyConfig struct {
+	Default string              // ISO 4217 code used when a product price has no currency
+	Rates   map[string]*big.Rat // Units of each currency per one unit of Default, for display conversion
+}
+
+func loadCurrencyConfig() CurrencyConfig {
+	logger.Debug().Msg("Loading currency configuration")
+
+	config := CurrencyConfig{
+		Default: strings.ToUpper(strings.TrimSpace(getEnv("CURRENCY_DEFAULT", "USD"))),
+		Rates:   parseCurrencyRates(getEnv("CURRENCY_RATES", "")),
+	}
+
+	currencies := make([]string, 0, len(config.Rates))
+	for code := range config.Rates {
+		currencies = append(currencies, code)
+	}
+	sort.Strings(currencies)
+
+	logger.Info().
+		Str("default_currency", config.Default).
+		Strs("rate_currencies", currencies).
+		Msg("Currency configuration loaded")
+
+	return config
+}
+
+// parseCurrencyRates parses a list such as "EUR=0.92,GBP=0.79". Rates are
+// parsed as exact fractions; invalid entries are skipped with a warning.
+func parseCurrencyRates(raw string) map[string]*big.Rat {
+	rates := make(map[string]*big.Rat)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		code, value, ok := strings.Cut(entry, "=")
+		rate, valid := new(big.Rat).SetString(strings.TrimSpace(value))
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if !ok || !valid || rate.Sign() <= 0 || len(code) != 3 {
+			logger.Warn().Str("entry", entry).Msg("Ignoring invalid currency rate")
+			continue
+		}
+		rates[code] = rate
+	}
+	return rates
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"goapi-starter/internal/logger"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ProductsConfig struct {
+	TrashRetention     time.Duration // How long soft-deleted products stay restorable
+	TrashPurgeInterval time.Duration // How often expired products are purged from the trash
+	ExportDir          string        // Local directory for asynchronously generated exports
+	ReservationTTL     time.Duration // Default lifetime of an unconfirmed stock reservation
+	ImageMaxBytes      int64         // Largest accepted image upload
+	ImageMaxPixels     int           // Largest accepted image area, guarding against decompression bombs
+	ThumbnailSizes     []int         // Longest edge of each generated thumbnail
+	ImageURLTTL        time.Duration // Lifetime of signed image download URLs
+}
+
+func loadProductsConfig() ProductsConfig {
+	logger.Debug().Msg("Loading products configuration")
+
+	config := ProductsConfig{
+		TrashRetention:     time.Duration(getEnvAsInt("PRODUCT_TRASH_RETENTION_HOURS", 720)) * time.Hour,
+		TrashPurgeInterval: time.Duration(getEnvAsInt("PRODUCT_TRASH_PURGE_INTERVAL_MINUTES", 60)) * time.Minute,
+		ExportDir:          getEnv("PRODUCT_EXPORT_DIR", filepath.Join(os.TempDir(), "goapi-exports")),
+		ReservationTTL:     time.Duration(getEnvAsInt("PRODUCT_RESERVATION_TTL_MINUTES", 15)) * time.Minute,
+		ImageMaxBytes:      int64(getEnvAsInt("PRODUCT_IMAGE_MAX_BYTES", 10<<20)),
+		ImageMaxPixels:     getEnvAsInt("PRODUCT_IMAGE_MAX_PIXELS", 40_000_000),
+		ThumbnailSizes:     parseThumbnailSizes(getEnv("PRODUCT_IMAGE_THUMBNAIL_SIZES", "128,512")),
+		ImageURLTTL:        time.Duration(getEnvAsInt("PRODUCT_IMAGE_URL_TTL_MINUTES", 15)) * time.Minute,
+	}
+
+	logger.Info().
+		Dur("trash_retention", config.TrashRetention).
+		Dur("trash_purge_interval", config.TrashPurgeInterval).
+		Str("export_dir", config.ExportDir).
+		Dur("reservation_ttl", config.ReservationTTL).
+		Int64("image_max_bytes", config.ImageMaxBytes).
+		Ints("thumbnail_sizes", config.ThumbnailSizes).
+		Dur("image_url_ttl", config.ImageURLTTL).
+		Msg("Products configuration loaded")
+
+	return config
+}
+
+// parseThumbnailSizes parses a list such as "128,512", skipping invalid and
+// duplicate sizes
+func parseThumbnailSizes(raw string) []int {
+	var sizes []int
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		size, err := strconv.Atoi(part)
+		if err != nil || size <= 0 || size > 4096 {
+			logger.Warn().Str("size", part).Msg("Ignoring invalid thumbnail size")
+			continue
+		}
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+	return slices.Compact(sizes)
+}
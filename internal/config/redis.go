@@ -7,11 +7,35 @@ import (
 )
 
 type RedisConfig struct {
-	Host     string
-	Port     string
+	Mode     string   // standalone, sentinel or cluster
+	Host     string   // Standalone server, used when Addrs is empty
+	Port     string   // Standalone server port, used when Addrs is empty
+	Addrs    []string // Sentinel or cluster node addresses (host:port)
+	Username string   // ACL user; empty for the default user
 	Password string
-	DB       int
+	DB       int // Ignored in cluster mode
+
+	SentinelMaster   string // Name of the master monitored by the sentinels
+	SentinelUsername string
+	SentinelPassword string
+
+	TLSEnabled            bool
+	TLSServerName         string // Overrides the name verified in the server certificate
+	TLSCAFile             string // PEM bundle trusted instead of the system roots
+	TLSInsecureSkipVerify bool
+
+	PoolSize        int           // Connections per node; 0 uses 10 per CPU
+	MinIdleConns    int           // Idle connections kept open per node
+	DialTimeout     time.Duration // Timeout for establishing connections
+	ReadTimeout     time.Duration // Timeout for socket reads
+	WriteTimeout    time.Duration // Timeout for socket writes
+	PoolTimeout     time.Duration // How long a command waits for a free connection
+	MaxRetries      int           // Retries per command; -1 disables them
+	MinRetryBackoff time.Duration // Backoff before the first retry, doubling up to MaxRetryBackoff
+	MaxRetryBackoff time.Duration
+
 	CacheTTL time.Duration // Default TTL for cached items
+	StaleTTL time.Duration // How long expired GetOrLoad entries are served while refreshing
 }
 
 func loadRedisConfig() RedisConfig {
@@ -19,21 +43,62 @@ func loadRedisConfig() RedisConfig {
 
 	db, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
 	cacheTTL, _ := strconv.Atoi(getEnv("REDIS_CACHE_TTL", "3600"))
+	staleTTL, _ := strconv.Atoi(getEnv("REDIS_CACHE_STALE_TTL", "60"))
+	tlsEnabled, _ := strconv.ParseBool(getEnv("REDIS_TLS_ENABLED", "false"))
+	tlsInsecure, _ := strconv.ParseBool(getEnv("REDIS_TLS_INSECURE_SKIP_VERIFY", "false"))
 
 	config := RedisConfig{
+		Mode:     getEnv("REDIS_MODE", "standalone"),
 		Host:     getEnv("REDIS_HOST", "localhost"),
 		Port:     getEnv("REDIS_PORT", "6379"),
+		Addrs:    splitList(getEnv("REDIS_ADDRS", "")),
+		Username: getEnv("REDIS_USERNAME", ""),
 		Password: getEnv("REDIS_PASSWORD", ""),
 		DB:       db,
+
+		SentinelMaster:   getEnv("REDIS_SENTINEL_MASTER", "mymaster"),
+		SentinelUsername: getEnv("REDIS_SENTINEL_USERNAME", ""),
+		SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+
+		TLSEnabled:            tlsEnabled,
+		TLSServerName:         getEnv("REDIS_TLS_SERVER_NAME", ""),
+		TLSCAFile:             getEnv("REDIS_TLS_CA_FILE", ""),
+		TLSInsecureSkipVerify: tlsInsecure,
+
+		PoolSize:        getEnvAsInt("REDIS_POOL_SIZE", 0),
+		MinIdleConns:    getEnvAsInt("REDIS_MIN_IDLE_CONNS", 0),
+		DialTimeout:     time.Duration(getEnvAsInt("REDIS_DIAL_TIMEOUT_MS", 5000)) * time.Millisecond,
+		ReadTimeout:     time.Duration(getEnvAsInt("REDIS_READ_TIMEOUT_MS", 3000)) * time.Millisecond,
+		WriteTimeout:    time.Duration(getEnvAsInt("REDIS_WRITE_TIMEOUT_MS", 3000)) * time.Millisecond,
+		PoolTimeout:     time.Duration(getEnvAsInt("REDIS_POOL_TIMEOUT_MS", 4000)) * time.Millisecond,
+		MaxRetries:      getEnvAsInt("REDIS_MAX_RETRIES", 3),
+		MinRetryBackoff: time.Duration(getEnvAsInt("REDIS_MIN_RETRY_BACKOFF_MS", 8)) * time.Millisecond,
+		MaxRetryBackoff: time.Duration(getEnvAsInt("REDIS_MAX_RETRY_BACKOFF_MS", 512)) * time.Millisecond,
+
 		CacheTTL: time.Duration(cacheTTL) * time.Second,
+		StaleTTL: time.Duration(staleTTL) * time.Second,
 	}
 
 	logger.Info().
+		Str("redis_mode", config.Mode).
 		Str("redis_host", config.Host).
 		Str("redis_port", config.Port).
+		Strs("redis_addrs", config.Addrs).
 		Int("redis_db", config.DB).
+		Bool("redis_tls", config.TLSEnabled).
+		Int("redis_pool_size", config.PoolSize).
+		Int("redis_max_retries", config.MaxRetries).
 		Dur("cache_ttl", config.CacheTTL).
+		Dur("stale_ttl", config.StaleTTL).
 		Msg("Redis configuration loaded")
 
 	return config
 }
+
+// RedisAddrs returns the configured node addresses, falling back to Host and Port
+func (c RedisConfig) RedisAddrs() []string {
+	if len(c.Addrs) > 0 {
+		return c.Addrs
+	}
+	return []string{c.Host + ":" + c.Port}
+}
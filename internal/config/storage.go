@@ -0,0 +1,56 @@
+package config
+
+import (
+	"goapi-starter/internal/logger"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type StorageConfig struct {
+	Driver        string // "local" or "s3"
+	LocalDir      string // Root directory of the local driver
+	PublicURL     string // Base URL of this API, used for locally signed download URLs
+	SigningSecret string // HMAC key for locally signed download URLs
+	S3            S3Config
+}
+
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.eu-west-1.amazonaws.com or http://localhost:9000 for MinIO
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UsePathStyle    bool // Address the bucket in the path instead of the host name
+}
+
+func loadStorageConfig() StorageConfig {
+	logger.Debug().Msg("Loading storage configuration")
+
+	usePathStyle, _ := strconv.ParseBool(getEnv("S3_USE_PATH_STYLE", "true"))
+
+	config := StorageConfig{
+		Driver:        getEnv("STORAGE_DRIVER", "local"),
+		LocalDir:      getEnv("STORAGE_LOCAL_DIR", filepath.Join(os.TempDir(), "goapi-storage")),
+		PublicURL:     getEnv("STORAGE_PUBLIC_URL", "http://localhost:"+getEnv("SERVER_PORT", "3000")),
+		SigningSecret: getEnv("STORAGE_SIGNING_SECRET", "default-storage-secret"),
+		S3: S3Config{
+			Endpoint:        getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:          getEnv("S3_REGION", "us-east-1"),
+			Bucket:          getEnv("S3_BUCKET", ""),
+			AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+			UsePathStyle:    usePathStyle,
+		},
+	}
+
+	logger.Info().
+		Str("driver", config.Driver).
+		Str("local_dir", config.LocalDir).
+		Str("public_url", config.PublicURL).
+		Str("s3_endpoint", config.S3.Endpoint).
+		Str("s3_bucket", config.S3.Bucket).
+		Msg("Storage configuration loaded")
+
+	return config
+}
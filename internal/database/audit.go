@@ -0,0 +1,47 @@
+package database
+
+import (
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// setAuditContextSQL exposes the actor and correlation ID of the current
+// request to the history triggers. The settings are transaction-local, so they
+// never leak to other requests sharing the pooled connection.
+const setAuditContextSQL = `SELECT set_config('app.actor_id', $1, true), set_config('app.correlation_id', $2, true)`
+
+// AddAuditCallbacks makes every write that runs inside a transaction with a
+// request context record who made it in the change history
+func AddAuditCallbacks() {
+	logger.Debug().Msg("Adding database audit callbacks")
+
+	DB.Callback().Create().Before("gorm:create").Register("audit:create", setAuditContext)
+	DB.Callback().Update().Before("gorm:update").Register("audit:update", setAuditContext)
+	DB.Callback().Delete().Before("gorm:delete").Register("audit:delete", setAuditContext)
+	DB.Callback().Raw().Before("gorm:raw").Register("audit:raw", setAuditContext)
+	DB.Callback().Row().Before("gorm:row").Register("audit:row", setAuditContext)
+}
+
+func setAuditContext(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil {
+		return
+	}
+
+	// Outside a transaction the settings would end with the statement itself
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); !ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	actorID, _ := utils.GetUserIDFromContext(ctx)
+	correlationID := utils.GetCorrelationID(ctx)
+	if actorID == "" && correlationID == "" {
+		return
+	}
+
+	if _, err := db.Statement.ConnPool.ExecContext(ctx, setAuditContextSQL, actorID, correlationID); err != nil {
+		logger.Warn().Err(err).Msg("Failed to set audit context")
+	}
+}
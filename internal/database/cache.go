@@ -0,0 +1,35 @@
+package database
+
+import (
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/logger"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// AddCacheCallbacks clears cached not found results for every created record,
+// so a resource looked up before it existed is visible as soon as it is created
+func AddCacheCallbacks() {
+	logger.Debug().Msg("Adding database cache callbacks")
+
+	DB.Callback().Create().After("gorm:create").Register("cache:clear_not_found", clearNotFound)
+}
+
+func clearNotFound(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		records := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			records = append(records, value.Index(i).Interface())
+		}
+		cache.ClearNotFound(records...)
+	case reflect.Struct:
+		cache.ClearNotFound(value.Interface())
+	}
+}
@@ -49,4 +49,10 @@ func InitDB() {
 
 	// Add metrics callbacks
 	AddMetricsCallbacks()
+
+	// Record the actor of writes in the change history
+	AddAuditCallbacks()
+
+	// Drop cached not found results for created records
+	AddCacheCallbacks()
 }
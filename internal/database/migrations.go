@@ -0,0 +1,363 @@
+package database
+
+import (
+	"goapi-starter/internal/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a single, versioned schema change. Migrations are applied in
+// order, each inside its own transaction, and are never modified once released.
+type Migration struct {
+	ID string
+	Up func(tx *gorm.DB) error
+}
+
+// SchemaMigration records which migrations have been applied
+type SchemaMigration struct {
+	ID        string    `gorm:"primaryKey;size:255"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// migrations is the ordered list of schema changes. Append new entries at the end.
+var migrations = []Migration{
+	{
+		// Creates the tables previously produced by AutoMigrate plus the price
+		// and created_at indexes used by keyset pagination. IF NOT EXISTS lets
+		// existing databases pick up the migration history, only gaining the
+		// indexes where they are missing.
+		ID: "0001_initial_schema",
+		Up: execStatements(
+			`CREATE TABLE IF NOT EXISTS users (
+				id uuid DEFAULT gen_random_uuid(),
+				username text NOT NULL,
+				email text NOT NULL,
+				password text NOT NULL,
+				created_at timestamptz,
+				updated_at timestamptz,
+				deleted_at timestamptz,
+				PRIMARY KEY (id)
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email)`,
+			`CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at)`,
+			`CREATE TABLE IF NOT EXISTS dummy_products (
+				id bigserial,
+				name varchar(100) NOT NULL,
+				description varchar(500),
+				price decimal NOT NULL,
+				created_at timestamptz,
+				updated_at timestamptz,
+				PRIMARY KEY (id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_products_price ON dummy_products (price)`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_products_created_at ON dummy_products (created_at)`,
+			`CREATE TABLE IF NOT EXISTS refresh_tokens (
+				id uuid DEFAULT gen_random_uuid(),
+				user_id uuid NOT NULL,
+				token text NOT NULL,
+				expires_at timestamptz,
+				created_at timestamptz,
+				deleted_at timestamptz,
+				PRIMARY KEY (id),
+				CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id)
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token ON refresh_tokens (token)`,
+			`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_deleted_at ON refresh_tokens (deleted_at)`,
+		),
+	},
+	{
+		// Full-text search over name and description, plus trigram indexes for
+		// typo-tolerant matching on the product name
+		ID: "0002_dummy_product_search",
+		Up: execStatements(
+			`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+			`ALTER TABLE dummy_products ADD COLUMN IF NOT EXISTS search_vector tsvector
+				GENERATED ALWAYS AS (
+					setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+					setweight(to_tsvector('english', coalesce(description, '')), 'B')
+				) STORED`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_products_search_vector ON dummy_products USING GIN (search_vector)`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_products_name_trgm ON dummy_products USING GIN (name gin_trgm_ops)`,
+		),
+	},
+	{
+		// Row version used for optimistic concurrency control (ETag / If-Match)
+		ID: "0003_dummy_product_version",
+		Up: execStatements(
+			`ALTER TABLE dummy_products ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1`,
+		),
+	},
+	{
+		// Soft delete with a restorable trash
+		ID: "0004_dummy_product_soft_delete",
+		Up: execStatements(
+			`ALTER TABLE dummy_products ADD COLUMN IF NOT EXISTS deleted_at timestamptz`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_products_deleted_at ON dummy_products (deleted_at)`,
+		),
+	},
+	{
+		// Category tree (materialized path) and free-form tags
+		ID: "0005_categories_and_tags",
+		Up: execStatements(
+			`CREATE TABLE IF NOT EXISTS categories (
+				id bigserial,
+				name varchar(100) NOT NULL,
+				slug varchar(100) NOT NULL,
+				parent_id bigint,
+				path text NOT NULL,
+				depth integer NOT NULL DEFAULT 0,
+				created_at timestamptz,
+				updated_at timestamptz,
+				PRIMARY KEY (id),
+				CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories (id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_categories_path ON categories (path text_pattern_ops)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_parent_slug ON categories (COALESCE(parent_id, 0), slug)`,
+			`CREATE TABLE IF NOT EXISTS tags (
+				id bigserial,
+				name varchar(50) NOT NULL,
+				created_at timestamptz,
+				PRIMARY KEY (id)
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name)`,
+			`ALTER TABLE dummy_products ADD COLUMN IF NOT EXISTS category_id bigint
+				CONSTRAINT fk_dummy_products_category REFERENCES categories (id) ON DELETE SET NULL`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_products_category_id ON dummy_products (category_id)`,
+			`CREATE TABLE IF NOT EXISTS dummy_product_tags (
+				dummy_product_id bigint NOT NULL,
+				tag_id bigint NOT NULL,
+				PRIMARY KEY (dummy_product_id, tag_id),
+				CONSTRAINT fk_dummy_product_tags_product FOREIGN KEY (dummy_product_id) REFERENCES dummy_products (id) ON DELETE CASCADE,
+				CONSTRAINT fk_dummy_product_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_product_tags_tag_id ON dummy_product_tags (tag_id)`,
+		),
+	},
+	{
+		// Inventory with reservations. The check constraint is the last line of
+		// defence against overselling: reserved units can never exceed stock.
+		ID: "0006_inventory",
+		Up: execStatements(
+			`ALTER TABLE dummy_products ADD COLUMN IF NOT EXISTS stock_quantity integer NOT NULL DEFAULT 0`,
+			`ALTER TABLE dummy_products ADD COLUMN IF NOT EXISTS reserved_quantity integer NOT NULL DEFAULT 0`,
+			`ALTER TABLE dummy_products ADD COLUMN IF NOT EXISTS low_stock_threshold integer NOT NULL DEFAULT 5`,
+			`DO $$ BEGIN
+				ALTER TABLE dummy_products ADD CONSTRAINT chk_dummy_products_stock
+					CHECK (reserved_quantity >= 0 AND reserved_quantity <= stock_quantity);
+			EXCEPTION WHEN duplicate_object THEN NULL;
+			END $$`,
+			`CREATE TABLE IF NOT EXISTS stock_reservations (
+				id uuid DEFAULT gen_random_uuid(),
+				dummy_product_id bigint NOT NULL,
+				user_id uuid NOT NULL,
+				quantity integer NOT NULL CHECK (quantity > 0),
+				status varchar(20) NOT NULL,
+				expires_at timestamptz NOT NULL,
+				created_at timestamptz,
+				updated_at timestamptz,
+				PRIMARY KEY (id),
+				CONSTRAINT fk_stock_reservations_product FOREIGN KEY (dummy_product_id) REFERENCES dummy_products (id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_stock_reservations_dummy_product_id ON stock_reservations (dummy_product_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_stock_reservations_pending_expiry ON stock_reservations (expires_at) WHERE status = 'pending'`,
+		),
+	},
+	{
+		// Prices were written from float64 values; existing rows are priced in
+		// USD and normalized to whole cents
+		ID: "0007_price_currency",
+		Up: execStatements(
+			`ALTER TABLE dummy_products ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'USD'`,
+			`UPDATE dummy_products SET price = round(price, 2) WHERE scale(price) <> 2`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_products_currency_price ON dummy_products (currency, price)`,
+		),
+	},
+	{
+		// Every change to a product is recorded by a trigger, so writes made
+		// through raw SQL, cascades and background jobs are captured as well.
+		// Existing products get a baseline entry to reconstruct from.
+		ID: "0008_dummy_product_history",
+		Up: execStatements(
+			`CREATE TABLE IF NOT EXISTS dummy_product_history (
+				id bigserial,
+				dummy_product_id bigint NOT NULL,
+				action varchar(10) NOT NULL,
+				version bigint,
+				actor_id uuid,
+				correlation_id varchar(100),
+				changes jsonb NOT NULL,
+				changed_at timestamptz NOT NULL DEFAULT clock_timestamp(),
+				PRIMARY KEY (id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_product_history_product ON dummy_product_history (dummy_product_id, changed_at)`,
+			`CREATE OR REPLACE FUNCTION record_dummy_product_history() RETURNS trigger AS $$
+			DECLARE
+				old_row jsonb := '{}';
+				new_row jsonb := '{}';
+				row_id bigint;
+				row_version bigint;
+				diff jsonb;
+				change_action text;
+			BEGIN
+				IF TG_OP <> 'INSERT' THEN
+					old_row := to_jsonb(OLD) - 'search_vector';
+					row_id := OLD.id;
+					row_version := OLD.version;
+				END IF;
+				IF TG_OP <> 'DELETE' THEN
+					new_row := to_jsonb(NEW) - 'search_vector';
+					row_id := NEW.id;
+					row_version := NEW.version;
+				END IF;
+
+				SELECT coalesce(jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key)), '{}')
+				INTO diff
+				FROM jsonb_object_keys(old_row || new_row) AS key
+				WHERE (old_row -> key) IS DISTINCT FROM (new_row -> key);
+
+				IF diff = '{}' THEN
+					RETURN NULL;
+				END IF;
+
+				change_action := CASE
+					WHEN TG_OP = 'INSERT' THEN 'create'
+					WHEN TG_OP = 'DELETE' THEN 'purge'
+					WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+					WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+					ELSE 'update'
+				END;
+
+				INSERT INTO dummy_product_history (dummy_product_id, action, version, actor_id, correlation_id, changes)
+				VALUES (
+					row_id,
+					change_action,
+					row_version,
+					NULLIF(current_setting('app.actor_id', true), '')::uuid,
+					NULLIF(current_setting('app.correlation_id', true), ''),
+					diff
+				);
+				RETURN NULL;
+			END;
+			$$ LANGUAGE plpgsql`,
+			`DROP TRIGGER IF EXISTS trg_dummy_product_history ON dummy_products`,
+			`CREATE TRIGGER trg_dummy_product_history
+				AFTER INSERT OR UPDATE OR DELETE ON dummy_products
+				FOR EACH ROW EXECUTE FUNCTION record_dummy_product_history()`,
+			`INSERT INTO dummy_product_history (dummy_product_id, action, version, changes)
+				SELECT p.id, 'baseline', p.version,
+					(SELECT jsonb_object_agg(key, jsonb_build_object('old', NULL, 'new', value))
+					FROM jsonb_each(to_jsonb(p) - 'search_vector'))
+				FROM dummy_products p`,
+		),
+	},
+	{
+		// Image rows follow their product on purge; the blobs themselves are
+		// removed by the purge job, which reads the keys before deleting
+		ID: "0009_dummy_product_images",
+		Up: execStatements(
+			`CREATE TABLE IF NOT EXISTS dummy_product_images (
+				id uuid DEFAULT gen_random_uuid(),
+				dummy_product_id bigint NOT NULL REFERENCES dummy_products (id) ON DELETE CASCADE,
+				filename varchar(255),
+				content_type varchar(50) NOT NULL,
+				extension varchar(10) NOT NULL,
+				size bigint NOT NULL,
+				width integer NOT NULL,
+				height integer NOT NULL,
+				thumbnail_sizes varchar(100),
+				created_at timestamptz,
+				PRIMARY KEY (id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_product_images_dummy_product_id ON dummy_product_images (dummy_product_id, created_at)`,
+		),
+	},
+	{
+		ID: "0010_dummy_product_owner",
+		Up: execStatements(
+			`ALTER TABLE dummy_products ADD COLUMN IF NOT EXISTS owner_id uuid REFERENCES users (id) ON DELETE SET NULL`,
+			`CREATE INDEX IF NOT EXISTS idx_dummy_products_owner_id ON dummy_products (owner_id)`,
+		),
+	},
+	{
+		ID: "0011_user_role",
+		Up: execStatements(
+			`ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'user'`,
+		),
+	},
+}
+
+// execStatements returns a migration step that runs the given SQL statements in order
+func execStatements(statements ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// migrationLockID is the Postgres advisory lock key held while migrations run,
+// so instances starting at the same time apply each migration only once
+const migrationLockID int64 = 7_146_295_301
+
+// RunMigrations applies every migration that has not been recorded yet. It
+// holds an advisory lock on a dedicated connection for the whole run.
+func RunMigrations() error {
+	logger.Debug().Int("available", len(migrations)).Msg("Running database migrations")
+
+	return DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return err
+		}
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID).Error; err != nil {
+				logger.Error().Err(err).Msg("Failed to release the migration lock")
+			}
+		}()
+
+		return applyMigrations(conn)
+	})
+}
+
+// applyMigrations reads the applied set and applies the missing migrations.
+// The caller must hold the migration lock.
+func applyMigrations(conn *gorm.DB) error {
+	if err := conn.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+
+	var applied []SchemaMigration
+	if err := conn.Find(&applied).Error; err != nil {
+		return err
+	}
+
+	done := make(map[string]bool, len(applied))
+	for _, m := range applied {
+		done[m.ID] = true
+	}
+
+	for _, migration := range migrations {
+		if done[migration.ID] {
+			continue
+		}
+
+		logger.Info().Str("migration", migration.ID).Msg("Applying database migration")
+
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{ID: migration.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			logger.Error().Err(err).Str("migration", migration.ID).Msg("Database migration failed")
+			return err
+		}
+	}
+
+	return nil
+}
@@ -64,6 +64,7 @@ func SignUp(w http.ResponseWriter, r *http.Request) {
 		Username: req.Username,
 		Email:    req.Email,
 		Password: string(hashedPassword),
+		Role:     models.RoleUser,
 	}
 
 	if result := database.DB.Create(&user); result.Error != nil {
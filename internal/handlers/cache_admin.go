@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/utils"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetCachePrefixes lists the cache key prefixes with their key counts and memory use
+func GetCachePrefixes(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_cache_prefixes", "started").Inc()
+
+	prefixes, err := cache.KeyPrefixes()
+	if err != nil {
+		metrics.RecordHandlerError("GetCachePrefixes", "cache_error")
+		metrics.RecordDetailedError("GetCachePrefixes", "cache_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("get_cache_prefixes", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error scanning cache")
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_cache_prefixes", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Cache prefixes retrieved successfully",
+		Data:    prefixes,
+	})
+}
+
+// GetCacheKey returns the TTL and size of the key given by the key query parameter
+func GetCacheKey(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_cache_key", "started").Inc()
+
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		metrics.RecordHandlerError("GetCacheKey", "invalid_request")
+		metrics.RecordDetailedError("GetCacheKey", "invalid_request", "missing_key")
+		metrics.BusinessOperations.WithLabelValues("get_cache_key", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Missing key query parameter")
+		return
+	}
+
+	info, found, err := cache.InspectKey(key)
+	if err != nil {
+		metrics.RecordHandlerError("GetCacheKey", "cache_error")
+		metrics.RecordDetailedError("GetCacheKey", "cache_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("get_cache_key", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error inspecting cache key")
+		return
+	}
+	if !found {
+		metrics.RecordHandlerError("GetCacheKey", "not_found")
+		metrics.BusinessOperations.WithLabelValues("get_cache_key", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Cache key not found")
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_cache_key", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Cache key retrieved successfully",
+		Data:    info,
+	})
+}
+
+// PurgeCachePrefix deletes every key under a prefix, such as "dummy_products"
+func PurgeCachePrefix(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("purge_cache_prefix", "started").Inc()
+
+	prefix := chi.URLParam(r, "prefix")
+	if prefix == "" || strings.Contains(prefix, ":") {
+		metrics.RecordHandlerError("PurgeCachePrefix", "invalid_request")
+		metrics.RecordDetailedError("PurgeCachePrefix", "invalid_request", "invalid_prefix")
+		metrics.BusinessOperations.WithLabelValues("purge_cache_prefix", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid cache prefix")
+		return
+	}
+
+	deleted, err := cache.FlushPrefix(prefix + ":")
+	if errors.Is(err, cache.ErrProtectedPrefix) {
+		metrics.RecordHandlerError("PurgeCachePrefix", "forbidden")
+		metrics.RecordDetailedError("PurgeCachePrefix", "forbidden", "protected_prefix")
+		metrics.BusinessOperations.WithLabelValues("purge_cache_prefix", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusForbidden, "Cache prefix is protected")
+		return
+	}
+	if err != nil {
+		metrics.RecordHandlerError("PurgeCachePrefix", "cache_error")
+		metrics.RecordDetailedError("PurgeCachePrefix", "cache_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("purge_cache_prefix", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error purging cache prefix")
+		return
+	}
+
+	userID, _ := utils.GetUserIDFromContext(r.Context())
+	logger.Info().Str("prefix", prefix).Int64("deleted", deleted).Str("user_id", userID).Msg("Cache prefix purged")
+
+	metrics.BusinessOperations.WithLabelValues("purge_cache_prefix", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Cache prefix purged successfully",
+		Data:    map[string]interface{}{"prefix": prefix, "deleted": deleted},
+	})
+}
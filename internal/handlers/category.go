@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// errInvalidCategoryParent is returned when a category would become its own ancestor
+var errInvalidCategoryParent = errors.New("a category cannot be moved below itself")
+
+// slugify derives a URL-friendly slug from a name
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// categoryPath returns the materialized path of a category below parent
+func categoryPath(parent *models.Category, id uint) string {
+	if parent == nil {
+		return fmt.Sprintf("/%d/", id)
+	}
+	return fmt.Sprintf("%s%d/", parent.Path, id)
+}
+
+// invalidateCategorySubtreeProducts drops the cached copies of every product in
+// the subtree rooted at path, since their embedded category may have changed
+func invalidateCategorySubtreeProducts(path string) {
+	var ids []uint
+	err := database.DB.Model(&models.DummyProduct{}).
+		Where("category_id IN (SELECT id FROM categories WHERE path LIKE ?)", path+"%").
+		Pluck("id", &ids).Error
+	if err != nil {
+		logger.Warn().Err(err).Str("path", path).Msg("Failed to find products in category subtree")
+	}
+	cache.InvalidateDummyProducts(ids)
+}
+
+// loadCategoryParent loads the parent referenced by a request, if any
+func loadCategoryParent(parentID *uint) (*models.Category, error) {
+	if parentID == nil {
+		return nil, nil
+	}
+	var parent models.Category
+	if err := database.DB.First(&parent, *parentID).Error; err != nil {
+		return nil, err
+	}
+	return &parent, nil
+}
+
+// decodeCategoryRequest decodes and validates a category request body. It
+// writes the error response and returns false when the body is invalid.
+func decodeCategoryRequest(w http.ResponseWriter, r *http.Request, handler, operation string) (models.CategoryRequest, bool) {
+	var req models.CategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		metrics.RecordHandlerError(handler, "invalid_request")
+		metrics.RecordDetailedError(handler, "invalid_request", "json_decode_error")
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid request body")
+		return req, false
+	}
+
+	if req.Slug == "" {
+		req.Slug = slugify(req.Name)
+	} else {
+		req.Slug = slugify(req.Slug)
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		metrics.RecordHandlerError(handler, "validation_error")
+		metrics.RecordDetailedError(handler, "validation_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	if req.Slug == "" {
+		metrics.RecordHandlerError(handler, "validation_error")
+		metrics.RecordDetailedError(handler, "validation_error", "empty_slug")
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "slug must contain at least one letter or digit")
+		return req, false
+	}
+
+	return req, true
+}
+
+// GetCategories returns the category tree as a flat list in depth-first order.
+// With ?parent_id only the direct children of that category are returned.
+func GetCategories(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_categories", "started").Inc()
+
+	db := database.DB.Model(&models.Category{})
+	if raw := r.URL.Query().Get("parent_id"); raw != "" {
+		parentID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			metrics.RecordHandlerError("GetCategories", "invalid_request")
+			metrics.RecordDetailedError("GetCategories", "invalid_request", "invalid_parent_id")
+			metrics.BusinessOperations.WithLabelValues("get_categories", "failed").Inc()
+			utils.RespondWithError(w, r, http.StatusBadRequest, "parent_id must be a positive integer")
+			return
+		}
+		db = db.Where("parent_id = ?", parentID)
+	}
+
+	categories := []models.Category{}
+	if result := db.Order("path").Find(&categories); result.Error != nil {
+		metrics.RecordHandlerError("GetCategories", "database_error")
+		metrics.RecordDetailedError("GetCategories", "database_error", result.Error.Error())
+		metrics.BusinessOperations.WithLabelValues("get_categories", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error retrieving categories")
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_categories", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Categories retrieved successfully",
+		Data:    categories,
+	})
+}
+
+// GetCategory returns a specific category by ID
+func GetCategory(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_category", "started").Inc()
+
+	id := chi.URLParam(r, "id")
+
+	var category models.Category
+	if result := database.DB.First(&category, id); result.Error != nil {
+		metrics.RecordHandlerError("GetCategory", "not_found")
+		metrics.RecordDetailedError("GetCategory", "not_found", "id_"+id)
+		metrics.BusinessOperations.WithLabelValues("get_category", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_category", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Category retrieved successfully",
+		Data:    category,
+	})
+}
+
+// CreateCategory creates a category, optionally below a parent
+func CreateCategory(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("create_category", "started").Inc()
+
+	req, ok := decodeCategoryRequest(w, r, "CreateCategory", "create_category")
+	if !ok {
+		return
+	}
+
+	parent, err := loadCategoryParent(req.ParentID)
+	if err != nil {
+		metrics.RecordHandlerError("CreateCategory", "validation_error")
+		metrics.RecordDetailedError("CreateCategory", "validation_error", "unknown_parent")
+		metrics.BusinessOperations.WithLabelValues("create_category", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Parent category does not exist")
+		return
+	}
+
+	category := models.Category{Name: req.Name, Slug: req.Slug, ParentID: req.ParentID}
+	if parent != nil {
+		category.Depth = parent.Depth + 1
+	}
+
+	// The path includes the category's own ID, so it is set right after the insert
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		category.Path = categoryPath(parent, category.ID)
+		return tx.Model(&category).Update("path", category.Path).Error
+	})
+	if err != nil {
+		status, message := http.StatusInternalServerError, "Error creating category"
+		if strings.Contains(err.Error(), "duplicate") {
+			status, message = http.StatusConflict, "A category with this slug already exists here"
+		}
+		metrics.RecordHandlerError("CreateCategory", "database_error")
+		metrics.RecordDetailedError("CreateCategory", "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("create_category", "failed").Inc()
+		utils.RespondWithError(w, r, status, message)
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("create_category", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusCreated, utils.SuccessResponse{
+		Message: "Category created successfully",
+		Data:    category,
+	})
+}
+
+// UpdateCategory renames a category and/or moves it below another parent.
+// Moving rewrites the path of the whole subtree in a single statement.
+func UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("update_category", "started").Inc()
+
+	id := chi.URLParam(r, "id")
+
+	req, ok := decodeCategoryRequest(w, r, "UpdateCategory", "update_category")
+	if !ok {
+		return
+	}
+
+	var category models.Category
+	if result := database.DB.First(&category, id); result.Error != nil {
+		metrics.RecordHandlerError("UpdateCategory", "not_found")
+		metrics.RecordDetailedError("UpdateCategory", "not_found", "id_"+id)
+		metrics.BusinessOperations.WithLabelValues("update_category", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	parent, err := loadCategoryParent(req.ParentID)
+	if err != nil {
+		metrics.RecordHandlerError("UpdateCategory", "validation_error")
+		metrics.RecordDetailedError("UpdateCategory", "validation_error", "unknown_parent")
+		metrics.BusinessOperations.WithLabelValues("update_category", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Parent category does not exist")
+		return
+	}
+	if parent != nil && strings.HasPrefix(parent.Path, category.Path) {
+		metrics.RecordHandlerError("UpdateCategory", "validation_error")
+		metrics.RecordDetailedError("UpdateCategory", "validation_error", "cyclic_parent")
+		metrics.BusinessOperations.WithLabelValues("update_category", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, errInvalidCategoryParent.Error())
+		return
+	}
+
+	oldPath := category.Path
+	newPath := categoryPath(parent, category.ID)
+	newDepth := 0
+	if parent != nil {
+		newDepth = parent.Depth + 1
+	}
+	depthDelta := newDepth - category.Depth
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&category).Updates(map[string]interface{}{
+			"name":      req.Name,
+			"slug":      req.Slug,
+			"parent_id": req.ParentID,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if newPath == oldPath {
+			return bumpDummyProductVersions(tx, "category_id = ?", category.ID)
+		}
+
+		// Re-root every descendant by swapping the path prefix
+		err = tx.Exec(`UPDATE categories
+			SET path = ?::text || substr(path, ?::int), depth = depth + ?::int, updated_at = now()
+			WHERE path LIKE ?`, newPath, len(oldPath)+1, depthDelta, oldPath+"%").Error
+		if err != nil {
+			return err
+		}
+
+		// Every product in the subtree embeds a category whose path changed
+		return bumpDummyProductVersions(tx, "category_id IN (SELECT id FROM categories WHERE path LIKE ?)", newPath+"%")
+	})
+	if err != nil {
+		status, message := http.StatusInternalServerError, "Error updating category"
+		if strings.Contains(err.Error(), "duplicate") {
+			status, message = http.StatusConflict, "A category with this slug already exists here"
+		}
+		metrics.RecordHandlerError("UpdateCategory", "database_error")
+		metrics.RecordDetailedError("UpdateCategory", "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("update_category", "failed").Inc()
+		utils.RespondWithError(w, r, status, message)
+		return
+	}
+
+	database.DB.First(&category, category.ID)
+
+	// Products embed their category, and moves change subtree filters
+	invalidateCategorySubtreeProducts(category.Path)
+
+	metrics.BusinessOperations.WithLabelValues("update_category", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Category updated successfully",
+		Data:    category,
+	})
+}
+
+// DeleteCategory removes a leaf category. Its products become uncategorized.
+func DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("delete_category", "started").Inc()
+
+	id := chi.URLParam(r, "id")
+
+	var category models.Category
+	if result := database.DB.First(&category, id); result.Error != nil {
+		metrics.RecordHandlerError("DeleteCategory", "not_found")
+		metrics.RecordDetailedError("DeleteCategory", "not_found", "id_"+id)
+		metrics.BusinessOperations.WithLabelValues("delete_category", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	var children int64
+	database.DB.Model(&models.Category{}).Where("parent_id = ?", category.ID).Count(&children)
+	if children > 0 {
+		metrics.RecordHandlerError("DeleteCategory", "conflict")
+		metrics.RecordDetailedError("DeleteCategory", "conflict", "has_children")
+		metrics.BusinessOperations.WithLabelValues("delete_category", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusConflict, "Category has subcategories, delete or move them first")
+		return
+	}
+
+	// Collect the affected products before the foreign key detaches them
+	var productIDs []uint
+	database.DB.Model(&models.DummyProduct{}).Where("category_id = ?", category.ID).Pluck("id", &productIDs)
+
+	err := database.DB.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := bumpDummyProductVersions(tx, "category_id = ?", category.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&category).Error
+	})
+	if err != nil {
+		metrics.RecordHandlerError("DeleteCategory", "database_error")
+		metrics.RecordDetailedError("DeleteCategory", "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("delete_category", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error deleting category")
+		return
+	}
+
+	cache.InvalidateDummyProducts(productIDs)
+
+	metrics.BusinessOperations.WithLabelValues("delete_category", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Category deleted successfully",
+		Data:    nil,
+	})
+}
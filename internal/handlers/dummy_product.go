@@ -2,17 +2,21 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"goapi-starter/internal/cache"
+	"goapi-starter/internal/config"
 	"goapi-starter/internal/database"
 	"goapi-starter/internal/logger"
 	"goapi-starter/internal/metrics"
 	"goapi-starter/internal/models"
 	"goapi-starter/internal/utils"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
 )
 
 // CreateDummyProduct handles the creation of a new dummy product
@@ -27,6 +31,7 @@ func CreateDummyProduct(w http.ResponseWriter, r *http.Request) {
 		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	req = req.WithDefaultCurrency(defaultCurrency())
 
 	if err := utils.ValidateStruct(req); err != nil {
 		metrics.RecordHandlerError("CreateDummyProduct", "validation_error")
@@ -36,25 +41,28 @@ func CreateDummyProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dummyProduct := models.DummyProduct{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
+	if !checkCategoryForWrite(w, r, "CreateDummyProduct", "create_dummy_product", req.CategoryID) {
+		return
 	}
 
-	result := database.DB.Create(&dummyProduct)
-	if result.Error != nil {
+	dummyProduct := newDummyProduct(r.Context(), req)
+
+	err := database.DB.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&dummyProduct).Error; err != nil {
+			return err
+		}
+		return setDummyProductTags(tx, dummyProduct.ID, req.Tags)
+	})
+	if err != nil {
 		errorReason := "unknown"
-		if result.Error != nil {
-			if strings.Contains(result.Error.Error(), "duplicate") {
-				errorReason = "duplicate_entry"
+		if strings.Contains(err.Error(), "duplicate") {
+			errorReason = "duplicate_entry"
+		} else {
+			// Limit the error reason length to avoid cardinality explosion
+			if len(err.Error()) > 50 {
+				errorReason = err.Error()[:50]
 			} else {
-				// Limit the error reason length to avoid cardinality explosion
-				if len(result.Error.Error()) > 50 {
-					errorReason = result.Error.Error()[:50]
-				} else {
-					errorReason = result.Error.Error()
-				}
+				errorReason = err.Error()
 			}
 		}
 
@@ -65,63 +73,125 @@ func CreateDummyProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Return the product with its category and tags
+	preloadDummyProductRelations(database.DB).First(&dummyProduct, dummyProduct.ID)
+
 	// Invalidate the list cache since we've added a new product
-	if err := cache.Delete("dummy_products:all"); err != nil {
-		logger.Warn().Err(err).Msg("Failed to invalidate dummy products list cache")
-	}
+	cache.InvalidateDummyProductList()
 
 	metrics.BusinessOperations.WithLabelValues("create_dummy_product", "success").Inc()
+	w.Header().Set("ETag", dummyProductETag(dummyProduct))
 	utils.RespondWithJSON(w, r, http.StatusCreated, utils.SuccessResponse{
 		Message: "Dummy product created successfully",
 		Data:    dummyProduct,
 	})
 }
 
-// GetDummyProducts returns a list of all dummy products
+// GetDummyProducts returns a page of dummy products, honoring the filter,
+// sort and cursor query parameters
 func GetDummyProducts(w http.ResponseWriter, r *http.Request) {
 	metrics.BusinessOperations.WithLabelValues("get_dummy_products", "started").Inc()
 
-	// Try to get from cache first
-	var dummyProducts []models.DummyProduct
-	cacheKey := "dummy_products:all"
+	query, err := parseDummyProductListQuery(r.URL.Query())
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProducts", "invalid_request", "invalid_query")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	found, err := cache.Get(cacheKey, &dummyProducts)
+	displayCurrency, err := parseDisplayCurrency(r)
 	if err != nil {
-		logger.Warn().Err(err).Msg("Error retrieving from cache")
-		// Continue with database query
+		metrics.RecordHandlerError("GetDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProducts", "invalid_request", "invalid_display_currency")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	if found && len(dummyProducts) > 0 {
-		logger.Info().Msg("Returning dummy products from cache")
-		metrics.BusinessOperations.WithLabelValues("get_dummy_products", "success").Inc()
-		utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
-			Message: "Dummy products retrieved from cache",
-			Data:    dummyProducts,
-		})
+	selection, err := parseDummyProductSelection(r)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProducts", "invalid_request", "invalid_fields")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Not in cache, get from database
-	result := database.DB.Find(&dummyProducts)
-	if result.Error != nil {
+	db := database.DB.WithContext(r.Context())
+	listing, err := query.apply(selectDummyProductFields(db.Model(&models.DummyProduct{}), selection,
+		append(query.sortColumns(), displayPriceColumns(displayCurrency)...)...))
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProducts", "invalid_request", "invalid_cursor")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	load := func() (models.DummyProductPage, error) {
+		dummyProducts := []models.DummyProduct{}
+		if err := listing.Find(&dummyProducts).Error; err != nil {
+			return models.DummyProductPage{}, err
+		}
+
+		page := models.DummyProductPage{Items: dummyProducts, Limit: query.Limit}
+		if len(dummyProducts) > query.Limit {
+			page.Items = dummyProducts[:query.Limit]
+			var err error
+			if page.NextCursor, err = query.encodeCursor(page.Items[query.Limit-1]); err != nil {
+				logger.Warn().Err(err).Msg("Failed to encode next page cursor")
+			}
+		}
+
+		var err error
+		if page.TotalEstimate, err = estimateDummyProductCount(db, query); err != nil {
+			logger.Warn().Err(err).Msg("Failed to estimate dummy products count")
+		}
+		return page, nil
+	}
+
+	// Pages are cached per fieldset. Expanded relations change independently
+	// of the products, so those pages always come from the database.
+	var page models.DummyProductPage
+	if len(selection.Expand) == 0 {
+		cacheKey := query.cacheKey()
+		if selection.Sparse() {
+			cacheKey += ":" + selectionDigest(selection)
+		}
+		page, err = cache.GetOrLoad(cacheKey, config.AppConfig.Redis.CacheTTL, load, cache.DummyProductsTag)
+	} else {
+		page, err = load()
+	}
+	if err != nil {
 		metrics.RecordHandlerError("GetDummyProducts", "database_error")
-		metrics.RecordDetailedError("GetDummyProducts", "database_error", result.Error.Error())
+		metrics.RecordDetailedError("GetDummyProducts", "database_error", err.Error())
 		metrics.BusinessOperations.WithLabelValues("get_dummy_products", "failed").Inc()
 		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error retrieving dummy products")
 		return
 	}
 
-	// Store in cache for future requests
-	if len(dummyProducts) > 0 {
-		if err := cache.Set(cacheKey, dummyProducts); err != nil {
-			logger.Warn().Err(err).Msg("Failed to cache dummy products")
-		}
+	// Conversions are applied after caching so cached pages stay currency independent
+	setDisplayPrices(page.Items, displayCurrency)
+	respondWithDummyProductPage(w, r, page, selection, displayCurrency, "Dummy products retrieved successfully")
+}
+
+// respondWithDummyProductPage sends a page reduced to the requested fields
+func respondWithDummyProductPage(w http.ResponseWriter, r *http.Request, page models.DummyProductPage, selection utils.FieldSelection, displayCurrency, message string) {
+	data, err := projectDummyProductPage(page, selection, displayPriceKeys(displayCurrency)...)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProducts", "internal_error")
+		metrics.RecordDetailedError("GetDummyProducts", "internal_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("get_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error encoding dummy products")
+		return
 	}
 
 	metrics.BusinessOperations.WithLabelValues("get_dummy_products", "success").Inc()
 	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
-		Message: "Dummy products retrieved successfully",
-		Data:    dummyProducts,
+		Message: message,
+		Data:    data,
 	})
 }
 
@@ -138,49 +208,79 @@ func GetDummyProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Try to get from cache first
-	var dummyProduct models.DummyProduct
-	cacheKey := fmt.Sprintf("dummy_product:%s", id)
+	displayCurrency, err := parseDisplayCurrency(r)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProduct", "invalid_request", "invalid_display_currency")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	found, err := cache.Get(cacheKey, &dummyProduct)
+	selection, err := parseDummyProductSelection(r)
 	if err != nil {
-		logger.Warn().Err(err).Str("id", id).Msg("Error retrieving product from cache")
-		// Continue with database query
+		metrics.RecordHandlerError("GetDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProduct", "invalid_request", "invalid_fields")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	if found {
-		logger.Info().Str("id", id).Msg("Returning dummy product from cache")
-		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "success").Inc()
-		utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
-			Message: "Dummy product retrieved from cache",
-			Data:    dummyProduct,
-		})
+	if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+		getDummyProductAsOf(w, r, id, asOf, displayCurrency, selection)
 		return
 	}
 
-	// Not in cache, get from database
-	result := database.DB.First(&dummyProduct, id)
-	if result.Error != nil {
+	// Only the full representation is cached; selections are read with a
+	// narrowed select straight from the database
+	if !selection.IsZero() {
+		getDummyProductSelection(w, r, id, displayCurrency, selection)
+		return
+	}
+
+	productID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProduct", "invalid_request", "invalid_id")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid dummy product ID")
+		return
+	}
+
+	// Load the product with its category and tags through the cache, so a
+	// burst of requests for an expired product reaches the database once
+	dummyProduct, err := cache.GetOrLoad(cache.DummyProductKey(uint(productID)), config.AppConfig.Redis.CacheTTL, func() (models.DummyProduct, error) {
+		var dummyProduct models.DummyProduct
+		err := preloadDummyProductRelations(database.DB).First(&dummyProduct, productID).Error
+		return dummyProduct, err
+	}, cache.DummyProductTag(uint(productID)))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		metrics.RecordHandlerError("GetDummyProduct", "not_found")
 		metrics.RecordDetailedError("GetDummyProduct", "not_found", "id_"+id)
 		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
 		utils.RespondWithError(w, r, http.StatusNotFound, "Dummy product not found")
 		return
 	}
-
-	// Store in cache for future requests
-	if err := cache.Set(cacheKey, dummyProduct); err != nil {
-		logger.Warn().Err(err).Str("id", id).Msg("Failed to cache dummy product")
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProduct", "database_error")
+		metrics.RecordDetailedError("GetDummyProduct", "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error retrieving dummy product")
+		return
 	}
 
 	metrics.BusinessOperations.WithLabelValues("get_dummy_product", "success").Inc()
+	if utils.CheckNotModified(w, r, dummyProductETag(dummyProduct)) {
+		return
+	}
+	setDisplayPrice(&dummyProduct, displayCurrency)
 	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
 		Message: "Dummy product retrieved successfully",
 		Data:    dummyProduct,
 	})
 }
 
-// UpdateDummyProduct updates a specific dummy product
+// UpdateDummyProduct replaces a specific dummy product with the request body
 func UpdateDummyProduct(w http.ResponseWriter, r *http.Request) {
 	metrics.BusinessOperations.WithLabelValues("update_dummy_product", "started").Inc()
 
@@ -193,7 +293,7 @@ func UpdateDummyProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req models.UpdateDummyProductRequest
+	var req models.DummyProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		metrics.RecordHandlerError("UpdateDummyProduct", "invalid_request")
 		metrics.RecordDetailedError("UpdateDummyProduct", "invalid_request", "json_decode_error")
@@ -201,6 +301,7 @@ func UpdateDummyProduct(w http.ResponseWriter, r *http.Request) {
 		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	req = req.WithDefaultCurrency(defaultCurrency())
 
 	if err := utils.ValidateStruct(req); err != nil {
 		metrics.RecordHandlerError("UpdateDummyProduct", "validation_error")
@@ -210,66 +311,65 @@ func UpdateDummyProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if dummy product exists
-	var dummyProduct models.DummyProduct
-	if result := database.DB.First(&dummyProduct, id); result.Error != nil {
-		metrics.RecordHandlerError("UpdateDummyProduct", "not_found")
-		metrics.RecordDetailedError("UpdateDummyProduct", "not_found", "id_"+id)
-		metrics.BusinessOperations.WithLabelValues("update_dummy_product", "failed").Inc()
-		utils.RespondWithError(w, r, http.StatusNotFound, "Dummy product not found")
+	dummyProduct, ok := loadDummyProductForWrite(w, r, "UpdateDummyProduct", "update_dummy_product", id)
+	if !ok {
 		return
 	}
 
-	// Update fields if provided
-	updates := make(map[string]interface{})
-	if req.Name != nil {
-		updates["name"] = *req.Name
-	}
-	if req.Description != nil {
-		updates["description"] = *req.Description
-	}
-	if req.Price != nil {
-		updates["price"] = *req.Price
-	}
+	// PUT is a full replacement, so every writable field, the category and
+	// the tags are overwritten
+	saveDummyProductUpdate(w, r, "UpdateDummyProduct", "update_dummy_product", dummyProduct, req)
+}
 
-	if len(updates) == 0 {
-		metrics.RecordHandlerError("UpdateDummyProduct", "invalid_request")
-		metrics.RecordDetailedError("UpdateDummyProduct", "invalid_request", "no_updates")
-		metrics.BusinessOperations.WithLabelValues("update_dummy_product", "failed").Inc()
-		utils.RespondWithError(w, r, http.StatusBadRequest, "No updates provided")
-		return
+// getDummyProductSelection responds with the requested fields and expanded
+// relations of a product. Without a fieldset the category and tags are
+// embedded as in the full representation.
+func getDummyProductSelection(w http.ResponseWriter, r *http.Request, id, displayCurrency string, selection utils.FieldSelection) {
+	db := database.DB.WithContext(r.Context())
+	if !selection.Sparse() {
+		db = preloadDummyProductRelations(db)
 	}
+	db = selectDummyProductFields(db, selection, append(displayPriceColumns(displayCurrency), "version")...)
 
-	if result := database.DB.Model(&dummyProduct).Updates(updates); result.Error != nil {
-		metrics.RecordHandlerError("UpdateDummyProduct", "database_error")
-		metrics.RecordDetailedError("UpdateDummyProduct", "database_error", result.Error.Error())
-		metrics.BusinessOperations.WithLabelValues("update_dummy_product", "failed").Inc()
-		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error updating dummy product")
+	var dummyProduct models.DummyProduct
+	if result := db.First(&dummyProduct, id); result.Error != nil {
+		metrics.RecordHandlerError("GetDummyProduct", "not_found")
+		metrics.RecordDetailedError("GetDummyProduct", "not_found", "id_"+id)
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Dummy product not found")
 		return
 	}
 
-	// Get the updated dummy product
-	database.DB.First(&dummyProduct, id)
-
-	// Update the product in cache
-	cacheKey := fmt.Sprintf("dummy_product:%s", id)
-	if err := cache.Set(cacheKey, dummyProduct); err != nil {
-		logger.Warn().Err(err).Str("id", id).Msg("Failed to update dummy product in cache")
+	etag := dummyProductSelectionETag(dummyProduct, selection)
+	if utils.CheckNotModified(w, r, etag) {
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "success").Inc()
+		return
 	}
+	setDisplayPrice(&dummyProduct, displayCurrency)
+	respondWithDummyProductSelection(w, r, dummyProduct, selection, displayCurrency, "Dummy product retrieved successfully")
+}
 
-	// Invalidate the list cache since a product was updated
-	if err := cache.Delete("dummy_products:all"); err != nil {
-		logger.Warn().Err(err).Msg("Failed to invalidate dummy products list cache")
+// respondWithDummyProductSelection sends a product reduced to the requested fields
+func respondWithDummyProductSelection(w http.ResponseWriter, r *http.Request, dummyProduct models.DummyProduct, selection utils.FieldSelection, displayCurrency, message string) {
+	data, err := selection.Project(dummyProduct, displayPriceKeys(displayCurrency)...)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProduct", "internal_error")
+		metrics.RecordDetailedError("GetDummyProduct", "internal_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error encoding dummy product")
+		return
 	}
 
-	metrics.BusinessOperations.WithLabelValues("update_dummy_product", "success").Inc()
+	metrics.BusinessOperations.WithLabelValues("get_dummy_product", "success").Inc()
+	w.Header().Set("ETag", dummyProductSelectionETag(dummyProduct, selection))
 	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
-		Message: "Dummy product updated successfully",
-		Data:    dummyProduct,
+		Message: message,
+		Data:    data,
 	})
 }
 
-// DeleteDummyProduct deletes a specific dummy product
+// DeleteDummyProduct moves a specific dummy product to the trash. It can be
+// restored until the retention window expires and the purge job removes it.
 func DeleteDummyProduct(w http.ResponseWriter, r *http.Request) {
 	metrics.BusinessOperations.WithLabelValues("delete_dummy_product", "started").Inc()
 
@@ -282,18 +382,14 @@ func DeleteDummyProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if dummy product exists
-	var dummyProduct models.DummyProduct
-	if result := database.DB.First(&dummyProduct, id); result.Error != nil {
-		metrics.RecordHandlerError("DeleteDummyProduct", "not_found")
-		metrics.RecordDetailedError("DeleteDummyProduct", "not_found", "id_"+id)
-		metrics.BusinessOperations.WithLabelValues("delete_dummy_product", "failed").Inc()
-		utils.RespondWithError(w, r, http.StatusNotFound, "Dummy product not found")
+	dummyProduct, ok := loadDummyProductForWrite(w, r, "DeleteDummyProduct", "delete_dummy_product", id)
+	if !ok {
 		return
 	}
 
 	// Delete the dummy product
-	if result := database.DB.Delete(&dummyProduct); result.Error != nil {
+	result := database.DB.WithContext(r.Context()).Where("version = ?", dummyProduct.Version).Delete(&dummyProduct)
+	if result.Error != nil {
 		metrics.RecordHandlerError("DeleteDummyProduct", "database_error")
 		metrics.RecordDetailedError("DeleteDummyProduct", "database_error", result.Error.Error())
 		metrics.BusinessOperations.WithLabelValues("delete_dummy_product", "failed").Inc()
@@ -301,16 +397,20 @@ func DeleteDummyProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete the product from cache
-	cacheKey := fmt.Sprintf("dummy_product:%s", id)
-	if err := cache.Delete(cacheKey); err != nil {
-		logger.Warn().Err(err).Str("id", id).Msg("Failed to delete dummy product from cache")
+	if result.RowsAffected == 0 {
+		metrics.RecordHandlerError("DeleteDummyProduct", "conflict")
+		metrics.RecordDetailedError("DeleteDummyProduct", "conflict", "concurrent_update")
+		metrics.BusinessOperations.WithLabelValues("delete_dummy_product", "failed").Inc()
+		if r.Header.Get("If-Match") != "" {
+			utils.RespondWithError(w, r, http.StatusPreconditionFailed, "Dummy product has been modified")
+		} else {
+			utils.RespondWithError(w, r, http.StatusConflict, "Dummy product was modified concurrently, please retry")
+		}
+		return
 	}
 
-	// Invalidate the list cache since a product was deleted
-	if err := cache.Delete("dummy_products:all"); err != nil {
-		logger.Warn().Err(err).Msg("Failed to invalidate dummy products list cache")
-	}
+	// Drop every cached entry holding the product, list pages included
+	cache.InvalidateDummyProducts([]uint{dummyProduct.ID})
 
 	metrics.BusinessOperations.WithLabelValues("delete_dummy_product", "success").Inc()
 	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
@@ -318,3 +418,140 @@ func DeleteDummyProduct(w http.ResponseWriter, r *http.Request) {
 		Data:    nil,
 	})
 }
+
+// errConcurrentUpdate aborts a write whose version check matched no rows
+var errConcurrentUpdate = errors.New("dummy product was modified concurrently")
+
+// dummyProductETag returns the entity tag for the current version of a product
+func dummyProductETag(p models.DummyProduct) string {
+	return fmt.Sprintf(`"%d-%d"`, p.ID, p.Version)
+}
+
+// updateDummyProductIfVersion applies updates only when the stored version still
+// matches, bumping the version in the same statement. RowsAffected is zero when
+// another writer got there first.
+func updateDummyProductIfVersion(db *gorm.DB, id uint, version uint, updates map[string]interface{}) *gorm.DB {
+	updates["version"] = gorm.Expr("version + 1")
+	return db.Model(&models.DummyProduct{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(updates)
+}
+
+// bumpDummyProductVersions increments the version of every product matching the
+// condition. Products embed their category and tags, so changing those must
+// change the products' ETags too.
+func bumpDummyProductVersions(tx *gorm.DB, query string, args ...interface{}) error {
+	return tx.Unscoped().Model(&models.DummyProduct{}).
+		Where(query, args...).
+		UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
+
+// loadDummyProductForWrite loads the product about to be modified and enforces
+// the If-Match precondition. It writes the error response and returns false
+// when the request cannot proceed.
+func loadDummyProductForWrite(w http.ResponseWriter, r *http.Request, handler, operation, id string) (models.DummyProduct, bool) {
+	var dummyProduct models.DummyProduct
+	if result := preloadDummyProductRelations(database.DB).First(&dummyProduct, id); result.Error != nil {
+		metrics.RecordHandlerError(handler, "not_found")
+		metrics.RecordDetailedError(handler, "not_found", "id_"+id)
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Dummy product not found")
+		return dummyProduct, false
+	}
+
+	// Reject the write if the client edited an outdated representation
+	etag := dummyProductETag(dummyProduct)
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && !utils.ETagMatches(ifMatch, etag, false) {
+		metrics.RecordHandlerError(handler, "precondition_failed")
+		metrics.RecordDetailedError(handler, "precondition_failed", "etag_mismatch")
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		w.Header().Set("ETag", etag)
+		utils.RespondWithError(w, r, http.StatusPreconditionFailed, "Dummy product has been modified")
+		return dummyProduct, false
+	}
+
+	return dummyProduct, true
+}
+
+// checkCategoryForWrite rejects requests referencing a missing category. It
+// writes the error response and returns false when the request cannot proceed.
+func checkCategoryForWrite(w http.ResponseWriter, r *http.Request, handler, operation string, categoryID *uint) bool {
+	err := checkDummyProductCategory(database.DB, categoryID)
+	if err == nil {
+		return true
+	}
+
+	metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+	if errors.Is(err, errUnknownCategory) {
+		metrics.RecordHandlerError(handler, "validation_error")
+		metrics.RecordDetailedError(handler, "validation_error", "unknown_category")
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Category does not exist")
+		return false
+	}
+
+	metrics.RecordHandlerError(handler, "database_error")
+	metrics.RecordDetailedError(handler, "database_error", err.Error())
+	utils.RespondWithError(w, r, http.StatusInternalServerError, "Error updating dummy product")
+	return false
+}
+
+// saveDummyProductUpdate conditionally persists the request against the version
+// that was read, replaces the tags, refreshes the caches and writes the response
+func saveDummyProductUpdate(w http.ResponseWriter, r *http.Request, handler, operation string, dummyProduct models.DummyProduct, req models.DummyProductRequest) {
+	if !checkCategoryForWrite(w, r, handler, operation, req.CategoryID) {
+		return
+	}
+
+	// Only apply the update if nobody else changed the row since we read it
+	err := database.DB.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+		result := updateDummyProductIfVersion(tx, dummyProduct.ID, dummyProduct.Version, dummyProductUpdates(req))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errConcurrentUpdate
+		}
+		return setDummyProductTags(tx, dummyProduct.ID, req.Tags)
+	})
+
+	if errors.Is(err, errConcurrentUpdate) {
+		metrics.RecordHandlerError(handler, "conflict")
+		metrics.RecordDetailedError(handler, "conflict", "concurrent_update")
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		if r.Header.Get("If-Match") != "" {
+			utils.RespondWithError(w, r, http.StatusPreconditionFailed, "Dummy product has been modified")
+		} else {
+			utils.RespondWithError(w, r, http.StatusConflict, "Dummy product was modified concurrently, please retry")
+		}
+		return
+	}
+
+	if err != nil {
+		metrics.RecordHandlerError(handler, "database_error")
+		metrics.RecordDetailedError(handler, "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error updating dummy product")
+		return
+	}
+
+	// Get the updated dummy product
+	productID := dummyProduct.ID
+	dummyProduct = models.DummyProduct{}
+	preloadDummyProductRelations(database.DB).First(&dummyProduct, productID)
+	id := strconv.FormatUint(uint64(productID), 10)
+
+	// Update the product in cache
+	if err := cache.Prime(cache.DummyProductKey(productID), dummyProduct, config.AppConfig.Redis.CacheTTL, cache.DummyProductTag(productID)); err != nil {
+		logger.Warn().Err(err).Str("id", id).Msg("Failed to update dummy product in cache")
+	}
+
+	// Invalidate the list cache since a product was updated
+	cache.InvalidateDummyProductList()
+
+	metrics.BusinessOperations.WithLabelValues(operation, "success").Inc()
+	w.Header().Set("ETag", dummyProductETag(dummyProduct))
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Dummy product updated successfully",
+		Data:    dummyProduct,
+	})
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// BatchModeAtomic executes every operation in one transaction
+	BatchModeAtomic = "atomic"
+	// BatchModePartial executes each operation independently
+	BatchModePartial = "partial"
+
+	batchOpCreate = "create"
+	batchOpUpdate = "update"
+	batchOpDelete = "delete"
+)
+
+// errBatchOperationFailed rolls back the transaction of a failed operation
+var errBatchOperationFailed = errors.New("batch operation failed")
+
+// BatchDummyProducts executes a list of create, update and delete operations.
+// In atomic mode (the default) all operations share one transaction and the
+// first failure rolls everything back; in partial mode each operation commits
+// on its own. Product caches are invalidated once after the whole batch.
+func BatchDummyProducts(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("batch_dummy_products", "started").Inc()
+
+	var req models.DummyProductBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		metrics.RecordHandlerError("BatchDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("BatchDummyProducts", "invalid_request", "json_decode_error")
+		metrics.BusinessOperations.WithLabelValues("batch_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		metrics.RecordHandlerError("BatchDummyProducts", "validation_error")
+		metrics.RecordDetailedError("BatchDummyProducts", "validation_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("batch_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = BatchModeAtomic
+	}
+
+	response := models.DummyProductBatchResponse{
+		Mode:    req.Mode,
+		Results: make([]models.DummyProductBatchResult, len(req.Operations)),
+	}
+
+	var err error
+	if req.Mode == BatchModeAtomic {
+		err = runAtomicDummyProductBatch(r, req.Operations, &response)
+	} else {
+		runPartialDummyProductBatch(r, req.Operations, &response)
+	}
+
+	invalidateDummyProductBatchCaches(response)
+
+	if err != nil {
+		metrics.RecordHandlerError("BatchDummyProducts", "database_error")
+		metrics.RecordDetailedError("BatchDummyProducts", "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("batch_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error executing batch")
+		return
+	}
+
+	logger.Info().
+		Str("mode", response.Mode).
+		Int("operations", len(req.Operations)).
+		Int("succeeded", response.Succeeded).
+		Int("failed", response.Failed).
+		Bool("committed", response.Committed).
+		Msg("Dummy product batch executed")
+
+	if req.Mode == BatchModeAtomic && !response.Committed {
+		metrics.RecordHandlerError("BatchDummyProducts", "operation_failed")
+		metrics.BusinessOperations.WithLabelValues("batch_dummy_products", "failed").Inc()
+		utils.RespondWithJSON(w, r, http.StatusUnprocessableEntity, utils.SuccessResponse{
+			Message: "Batch rejected, no changes were applied",
+			Data:    response,
+		})
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("batch_dummy_products", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Batch executed",
+		Data:    response,
+	})
+}
+
+// runAtomicDummyProductBatch executes all operations in one transaction. When
+// an operation fails the others are reported as not applied. The returned
+// error is only set when the transaction itself could not be committed.
+func runAtomicDummyProductBatch(r *http.Request, ops []models.DummyProductBatchOperation, response *models.DummyProductBatchResponse) error {
+	failed := -1
+
+	err := database.DB.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+		for i, op := range ops {
+			response.Results[i] = executeDummyProductBatchOperation(tx, i, op)
+			if response.Results[i].Status >= http.StatusBadRequest {
+				failed = i
+				return errBatchOperationFailed
+			}
+		}
+		return nil
+	})
+
+	if err == nil {
+		response.Committed = true
+		response.Succeeded = len(ops)
+		return nil
+	}
+
+	// Nothing was applied, so report every other operation as a failed dependency
+	for i, op := range ops {
+		if i == failed {
+			continue
+		}
+		message := "not executed"
+		if i < failed {
+			message = "rolled back"
+		}
+		if failed >= 0 {
+			message = fmt.Sprintf("%s because operation %d failed", message, failed)
+		}
+		response.Results[i] = models.DummyProductBatchResult{
+			Index:  i,
+			Op:     op.Op,
+			ID:     op.ID,
+			Status: http.StatusFailedDependency,
+			Error:  message,
+		}
+	}
+	response.Failed = len(ops)
+
+	if errors.Is(err, errBatchOperationFailed) {
+		return nil
+	}
+	return err
+}
+
+// runPartialDummyProductBatch executes each operation in its own transaction
+func runPartialDummyProductBatch(r *http.Request, ops []models.DummyProductBatchOperation, response *models.DummyProductBatchResponse) {
+	for i, op := range ops {
+		err := database.DB.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			response.Results[i] = executeDummyProductBatchOperation(tx, i, op)
+			if response.Results[i].Status >= http.StatusBadRequest {
+				return errBatchOperationFailed
+			}
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errBatchOperationFailed) {
+			response.Results[i] = models.DummyProductBatchResult{
+				Index:  i,
+				Op:     op.Op,
+				ID:     op.ID,
+				Status: http.StatusInternalServerError,
+				Error:  "unable to commit operation",
+			}
+		}
+
+		if response.Results[i].Status >= http.StatusBadRequest {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	}
+	response.Committed = response.Succeeded > 0
+}
+
+// executeDummyProductBatchOperation runs a single operation inside tx and
+// describes its outcome with an HTTP status, mirroring the single-item endpoints
+func executeDummyProductBatchOperation(tx *gorm.DB, index int, op models.DummyProductBatchOperation) models.DummyProductBatchResult {
+	result := models.DummyProductBatchResult{Index: index, Op: op.Op, ID: op.ID}
+	fail := func(status int, message string) models.DummyProductBatchResult {
+		result.Status = status
+		result.Error = message
+		return result
+	}
+
+	switch op.Op {
+	case batchOpCreate, batchOpUpdate:
+		if op.Product == nil {
+			return fail(http.StatusBadRequest, "product is required")
+		}
+		product := op.Product.WithDefaultCurrency(defaultCurrency())
+		op.Product = &product
+		if err := utils.ValidateStruct(product); err != nil {
+			return fail(http.StatusBadRequest, err.Error())
+		}
+		if err := checkDummyProductCategory(tx, op.Product.CategoryID); err != nil {
+			if errors.Is(err, errUnknownCategory) {
+				return fail(http.StatusBadRequest, err.Error())
+			}
+			return fail(http.StatusInternalServerError, "unable to load category")
+		}
+	case batchOpDelete:
+	default:
+		return fail(http.StatusBadRequest, "op must be create, update or delete")
+	}
+
+	if op.Op == batchOpCreate {
+		product := newDummyProduct(tx.Statement.Context, *op.Product)
+		if err := tx.Create(&product).Error; err != nil {
+			return fail(http.StatusInternalServerError, "unable to create dummy product")
+		}
+		if err := setDummyProductTags(tx, product.ID, op.Product.Tags); err != nil {
+			return fail(http.StatusInternalServerError, "unable to tag dummy product")
+		}
+		if err := preloadDummyProductRelations(tx).First(&product, product.ID).Error; err != nil {
+			return fail(http.StatusInternalServerError, "unable to load dummy product")
+		}
+		result.ID = product.ID
+		result.Status = http.StatusCreated
+		result.Product = &product
+		return result
+	}
+
+	if op.ID == 0 {
+		return fail(http.StatusBadRequest, "id is required")
+	}
+
+	var product models.DummyProduct
+	if err := tx.First(&product, op.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fail(http.StatusNotFound, "dummy product not found")
+		}
+		return fail(http.StatusInternalServerError, "unable to load dummy product")
+	}
+	if op.Version != nil && *op.Version != product.Version {
+		return fail(http.StatusPreconditionFailed, "dummy product has been modified")
+	}
+
+	if op.Op == batchOpDelete {
+		deleted := tx.Where("version = ?", product.Version).Delete(&product)
+		if deleted.Error != nil {
+			return fail(http.StatusInternalServerError, "unable to delete dummy product")
+		}
+		if deleted.RowsAffected == 0 {
+			return fail(http.StatusConflict, "dummy product was modified concurrently")
+		}
+		result.Status = http.StatusOK
+		return result
+	}
+
+	updated := updateDummyProductIfVersion(tx, product.ID, product.Version, dummyProductUpdates(*op.Product))
+	if updated.Error != nil {
+		return fail(http.StatusInternalServerError, "unable to update dummy product")
+	}
+	if updated.RowsAffected == 0 {
+		return fail(http.StatusConflict, "dummy product was modified concurrently")
+	}
+	if err := setDummyProductTags(tx, product.ID, op.Product.Tags); err != nil {
+		return fail(http.StatusInternalServerError, "unable to tag dummy product")
+	}
+
+	if err := preloadDummyProductRelations(tx).First(&product, product.ID).Error; err != nil {
+		return fail(http.StatusInternalServerError, "unable to load dummy product")
+	}
+	result.Status = http.StatusOK
+	result.Product = &product
+	return result
+}
+
+// invalidateDummyProductBatchCaches drops the cached copies of every updated
+// or deleted product and invalidates the list cache once, instead of
+// once per operation
+func invalidateDummyProductBatchCaches(response models.DummyProductBatchResponse) {
+	if !response.Committed {
+		return
+	}
+
+	var ids []uint
+	for _, res := range response.Results {
+		if res.Status >= http.StatusBadRequest || res.Op == batchOpCreate {
+			continue
+		}
+		ids = append(ids, res.ID)
+	}
+	cache.InvalidateDummyProducts(ids)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/money"
+	"net/http"
+)
+
+// defaultCurrency is the currency of prices submitted without one
+func defaultCurrency() string {
+	return config.AppConfig.Currency.Default
+}
+
+// currencyRates returns the configured display conversion rates
+func currencyRates() money.Rates {
+	return money.Rates{Base: config.AppConfig.Currency.Default, PerBase: config.AppConfig.Currency.Rates}
+}
+
+// parseDisplayCurrency reads the optional display_currency parameter and
+// checks that prices can be converted into it
+func parseDisplayCurrency(r *http.Request) (string, error) {
+	currency := money.NormalizeCurrency(r.URL.Query().Get("display_currency"))
+	if currency == "" {
+		return "", nil
+	}
+	if _, err := currencyRates().Convert(money.Zero, currency, currency); err != nil {
+		return "", fmt.Errorf("display_currency %s is not supported", currency)
+	}
+	return currency, nil
+}
+
+// setDisplayPrices converts the price of each product into currency. Products
+// priced in a currency without a configured rate are left unconverted.
+func setDisplayPrices(products []models.DummyProduct, currency string) {
+	if currency == "" {
+		return
+	}
+
+	rates := currencyRates()
+	for i := range products {
+		converted, err := rates.Convert(products[i].Price, products[i].Currency, currency)
+		if err != nil {
+			logger.Warn().Err(err).Uint("id", products[i].ID).Msg("Unable to convert dummy product price")
+			continue
+		}
+		products[i].DisplayPrice = &converted
+	}
+}
+
+// setDisplayPrice converts the price of a single product into currency
+func setDisplayPrice(product *models.DummyProduct, currency string) {
+	products := []models.DummyProduct{*product}
+	setDisplayPrices(products, currency)
+	product.DisplayPrice = products[0].DisplayPrice
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/export"
+	"goapi-starter/internal/jobs"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// dummyProductExportJobType identifies export jobs in the job tracker
+const dummyProductExportJobType = "dummy_product_export"
+
+// dummyProductExportColumns is the column order of every export format
+var dummyProductExportColumns = []string{"id", "name", "description", "price", "currency", "category_id", "version", "created_at", "updated_at"}
+
+func dummyProductExportRow(p models.DummyProduct) []interface{} {
+	var categoryID interface{}
+	if p.CategoryID != nil {
+		categoryID = *p.CategoryID
+	}
+	return []interface{}{p.ID, p.Name, p.Description, export.Number(p.Price.String()), p.Currency, categoryID, p.Version, p.CreatedAt, p.UpdatedAt}
+}
+
+// exportFileName builds the Content-Disposition file name for an export
+func exportFileName(format string, at time.Time) string {
+	return fmt.Sprintf("dummy-products-%s.%s", at.UTC().Format("20060102T150405Z"), format)
+}
+
+// acceptsGzip reports whether the client accepts a gzip-encoded response
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if strings.TrimSpace(coding) != "gzip" {
+			continue
+		}
+		// An explicit q=0 means the client refuses gzip
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			return err == nil && q > 0
+		}
+		return true
+	}
+	return false
+}
+
+// compressResponse wraps w in a gzip writer when the client supports it.
+// XLSX files are already zip archives, so they are sent as is.
+func compressResponse(w http.ResponseWriter, r *http.Request, format string) (io.Writer, func() error) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	if format == export.FormatXLSX || !acceptsGzip(r) {
+		return w, func() error { return nil }
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	gz := gzip.NewWriter(w)
+	return gz, gz.Close
+}
+
+// writeDummyProductExport streams every product matching the query's filters
+// in its sort order. Rows are read one at a time from the database cursor, so
+// memory use does not grow with the size of the export.
+func writeDummyProductExport(ctx context.Context, query *productListQuery, format string, out io.Writer) (int, error) {
+	writer, err := export.NewWriter(format, out)
+	if err != nil {
+		return 0, err
+	}
+	if err := writer.WriteHeader(dummyProductExportColumns); err != nil {
+		return 0, err
+	}
+
+	db := query.applyOrder(query.applyFilters(database.DB.WithContext(ctx).Model(&models.DummyProduct{})))
+	rows, err := db.Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var product models.DummyProduct
+		if err := database.DB.ScanRows(rows, &product); err != nil {
+			return count, err
+		}
+		if err := writer.WriteRow(dummyProductExportRow(product)); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, writer.Close()
+}
+
+// ExportDummyProducts streams the dummy products matching the list filters as
+// CSV, NDJSON or XLSX. With ?async=true the file is generated in the
+// background and can be downloaded once the job has completed.
+func ExportDummyProducts(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("export_dummy_products", "started").Inc()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if !export.IsSupported(format) {
+		metrics.RecordHandlerError("ExportDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("ExportDummyProducts", "invalid_request", "invalid_format")
+		metrics.BusinessOperations.WithLabelValues("export_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "format must be csv, ndjson or xlsx")
+		return
+	}
+
+	query, err := parseDummyProductListQuery(r.URL.Query())
+	if err != nil {
+		metrics.RecordHandlerError("ExportDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("ExportDummyProducts", "invalid_request", "invalid_query")
+		metrics.BusinessOperations.WithLabelValues("export_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if async, _ := strconv.ParseBool(r.URL.Query().Get("async")); async {
+		startDummyProductExportJob(w, r, query, format)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFileName(format, time.Now())))
+	out, closeOut := compressResponse(w, r, format)
+
+	count, err := writeDummyProductExport(r.Context(), query, format, out)
+	if err == nil {
+		err = closeOut()
+	}
+	if err != nil {
+		// The status line has already been sent, so the only way to signal a
+		// truncated export is to abort the connection
+		logger.Error().Err(err).Int("rows", count).Msg("Dummy product export failed")
+		metrics.RecordHandlerError("ExportDummyProducts", "export_error")
+		metrics.RecordDetailedError("ExportDummyProducts", "export_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("export_dummy_products", "failed").Inc()
+		panic(http.ErrAbortHandler)
+	}
+
+	logger.Info().Str("format", format).Int("rows", count).Msg("Dummy product export streamed")
+	metrics.BusinessOperations.WithLabelValues("export_dummy_products", "success").Inc()
+}
+
+// startDummyProductExportJob generates the export into local file storage in
+// the background and responds with the job to poll
+func startDummyProductExportJob(w http.ResponseWriter, r *http.Request, query *productListQuery, format string) {
+	userID, _ := utils.GetUserIDFromContext(r.Context())
+	exportDir := config.AppConfig.Products.ExportDir
+
+	if err := os.MkdirAll(exportDir, 0o750); err != nil {
+		logger.Error().Err(err).Str("dir", exportDir).Msg("Unable to create export directory")
+		metrics.RecordHandlerError("ExportDummyProducts", "internal_error")
+		metrics.BusinessOperations.WithLabelValues("export_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error starting export")
+		return
+	}
+
+	job, err := jobs.NewJob(dummyProductExportJobType, userID)
+	if err != nil {
+		metrics.RecordHandlerError("ExportDummyProducts", "internal_error")
+		metrics.BusinessOperations.WithLabelValues("export_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error starting export")
+		return
+	}
+
+	go func() {
+		result, err := generateDummyProductExportFile(exportDir, job, query, format)
+		if err != nil {
+			logger.Error().Err(err).Str("job_id", job.ID).Msg("Dummy product export job failed")
+			job.Fail(err, nil)
+			return
+		}
+		job.Complete(result)
+	}()
+
+	metrics.BusinessOperations.WithLabelValues("export_dummy_products", "accepted").Inc()
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	utils.RespondWithJSON(w, r, http.StatusAccepted, utils.SuccessResponse{
+		Message: "Export started",
+		Data:    job,
+	})
+}
+
+// generateDummyProductExportFile writes the export to a temporary file and
+// renames it into place once complete, so downloads never see partial files
+func generateDummyProductExportFile(dir string, job *jobs.Job, query *productListQuery, format string) (models.DummyProductExportResult, error) {
+	fileName := job.ID + "." + format
+	path := filepath.Join(dir, fileName)
+
+	file, err := os.CreateTemp(dir, fileName+".*.partial")
+	if err != nil {
+		return models.DummyProductExportResult{}, err
+	}
+	defer os.Remove(file.Name())
+
+	job.SetProgress(map[string]string{"format": format})
+
+	count, err := writeDummyProductExport(context.Background(), query, format, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return models.DummyProductExportResult{}, err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return models.DummyProductExportResult{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return models.DummyProductExportResult{}, err
+	}
+
+	return models.DummyProductExportResult{
+		Format:      format,
+		Rows:        count,
+		Size:        info.Size(),
+		FileName:    exportFileName(format, job.CreatedAt),
+		DownloadURL: "/api/dummy-products/exports/" + job.ID,
+	}, nil
+}
+
+// DownloadDummyProductExport serves the file produced by a completed export job
+func DownloadDummyProductExport(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("download_dummy_product_export", "started").Inc()
+
+	id := chi.URLParam(r, "id")
+	userID, _ := utils.GetUserIDFromContext(r.Context())
+
+	job, found, err := jobs.GetJob(id)
+	if err != nil {
+		logger.Warn().Err(err).Str("job_id", id).Msg("Error retrieving export job")
+		metrics.RecordHandlerError("DownloadDummyProductExport", "cache_error")
+		metrics.BusinessOperations.WithLabelValues("download_dummy_product_export", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error retrieving export")
+		return
+	}
+
+	// Exports belonging to other users are reported as missing
+	if !found || job.OwnerID != userID || job.Type != dummyProductExportJobType {
+		metrics.RecordHandlerError("DownloadDummyProductExport", "not_found")
+		metrics.BusinessOperations.WithLabelValues("download_dummy_product_export", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Export not found")
+		return
+	}
+
+	if job.Status != jobs.StatusCompleted {
+		metrics.RecordHandlerError("DownloadDummyProductExport", "conflict")
+		metrics.RecordDetailedError("DownloadDummyProductExport", "conflict", "status_"+string(job.Status))
+		metrics.BusinessOperations.WithLabelValues("download_dummy_product_export", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusConflict, "Export is not ready, job status is "+string(job.Status))
+		return
+	}
+
+	var result models.DummyProductExportResult
+	if err := job.DecodeResult(&result); err != nil || !export.IsSupported(result.Format) {
+		metrics.RecordHandlerError("DownloadDummyProductExport", "internal_error")
+		metrics.BusinessOperations.WithLabelValues("download_dummy_product_export", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error retrieving export")
+		return
+	}
+
+	// Files live on the instance that generated them and are purged with the job
+	file, err := os.Open(filepath.Join(config.AppConfig.Products.ExportDir, job.ID+"."+result.Format))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, os.ErrNotExist) {
+			status = http.StatusGone
+		}
+		metrics.RecordHandlerError("DownloadDummyProductExport", "file_error")
+		metrics.RecordDetailedError("DownloadDummyProductExport", "file_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("download_dummy_product_export", "failed").Inc()
+		utils.RespondWithError(w, r, status, "Export file is no longer available")
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", export.ContentType(result.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", result.FileName))
+	out, closeOut := compressResponse(w, r, result.Format)
+
+	_, err = io.Copy(out, file)
+	if err == nil {
+		err = closeOut()
+	}
+	if err != nil {
+		logger.Warn().Err(err).Str("job_id", id).Msg("Export download interrupted")
+		metrics.RecordHandlerError("DownloadDummyProductExport", "write_error")
+		metrics.BusinessOperations.WithLabelValues("download_dummy_product_export", "failed").Inc()
+		panic(http.ErrAbortHandler)
+	}
+
+	metrics.BusinessOperations.WithLabelValues("download_dummy_product_export", "success").Inc()
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// dummyProductExpansions lists the relations ?expand= can embed in a product
+var dummyProductExpansions = []string{"owner", "category", "tags"}
+
+// parseDummyProductSelection reads the fields and expand query parameters
+func parseDummyProductSelection(r *http.Request) (utils.FieldSelection, error) {
+	return utils.ParseFieldSelection(r.URL.Query(), &models.DummyProduct{}, dummyProductExpansions...)
+}
+
+// selectDummyProductFields pushes a field selection down into the query. Only
+// the requested columns are selected, together with the required ones and the
+// foreign keys of expanded relations, and only expanded relations are loaded.
+func selectDummyProductFields(db *gorm.DB, selection utils.FieldSelection, required ...string) *gorm.DB {
+	required = append(required, "id")
+	if selection.Expands("owner") {
+		required = append(required, "owner_id")
+		db = db.Preload("Owner")
+	}
+	if selection.Expands("category") {
+		required = append(required, "category_id")
+		db = db.Preload("Category")
+	}
+	if selection.Expands("tags") {
+		db = db.Preload("Tags", func(db *gorm.DB) *gorm.DB {
+			return db.Order("tags.name")
+		})
+	}
+
+	if columns := selection.Columns(required...); columns != nil {
+		db = db.Select(columns)
+	}
+	return db
+}
+
+// displayPriceColumns are the columns a display_currency conversion reads
+func displayPriceColumns(displayCurrency string) []string {
+	if displayCurrency == "" {
+		return nil
+	}
+	return []string{"price", "currency"}
+}
+
+// displayPriceKeys keeps the converted price in projected responses
+func displayPriceKeys(displayCurrency string) []string {
+	if displayCurrency == "" {
+		return nil
+	}
+	return []string{"display_price"}
+}
+
+// selectionDigest shortens a selection for cache keys and ETags
+func selectionDigest(selection utils.FieldSelection) string {
+	sum := sha256.Sum256([]byte(selection.Key()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// dummyProductSelectionETag is the ETag of a product's representation. Sparse
+// and expanded responses differ from the full one, so they get their own tag.
+func dummyProductSelectionETag(p models.DummyProduct, selection utils.FieldSelection) string {
+	if selection.IsZero() {
+		return dummyProductETag(p)
+	}
+	return fmt.Sprintf(`"%d-%d-%s"`, p.ID, p.Version, selectionDigest(selection))
+}
+
+// projectDummyProductPage applies a sparse fieldset to the items of a page
+func projectDummyProductPage(page models.DummyProductPage, selection utils.FieldSelection, extra ...string) (interface{}, error) {
+	items, err := selection.Project(page.Items, extra...)
+	if err != nil || !selection.Sparse() {
+		return page, err
+	}
+
+	return struct {
+		Items         interface{} `json:"items"`
+		NextCursor    string      `json:"next_cursor,omitempty"`
+		Limit         int         `json:"limit"`
+		TotalEstimate int64       `json:"total_estimate,omitempty"`
+	}{items, page.NextCursor, page.Limit, page.TotalEstimate}, nil
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/services"
+	"goapi-starter/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// DefaultDummyProductHistoryPageSize is used when no limit is given
+	DefaultDummyProductHistoryPageSize = 50
+	// MaxDummyProductHistoryPageSize caps the limit query parameter
+	MaxDummyProductHistoryPageSize = 200
+)
+
+// GetDummyProductHistory returns the recorded changes of a dummy product,
+// newest first. Each entry lists the changed fields with their old and new
+// values, the acting user and the correlation ID of the request.
+func GetDummyProductHistory(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_dummy_product_history", "started").Inc()
+
+	productID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProductHistory", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProductHistory", "invalid_request", "invalid_id")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product_history", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid dummy product ID")
+		return
+	}
+
+	limit := DefaultDummyProductHistoryPageSize
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			metrics.RecordHandlerError("GetDummyProductHistory", "invalid_request")
+			metrics.RecordDetailedError("GetDummyProductHistory", "invalid_request", "invalid_limit")
+			metrics.BusinessOperations.WithLabelValues("get_dummy_product_history", "failed").Inc()
+			utils.RespondWithError(w, r, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = min(parsed, MaxDummyProductHistoryPageSize)
+	}
+
+	var before uint64
+	if raw := r.URL.Query().Get("before"); raw != "" {
+		if before, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			metrics.RecordHandlerError("GetDummyProductHistory", "invalid_request")
+			metrics.RecordDetailedError("GetDummyProductHistory", "invalid_request", "invalid_before")
+			metrics.BusinessOperations.WithLabelValues("get_dummy_product_history", "failed").Inc()
+			utils.RespondWithError(w, r, http.StatusBadRequest, "before must be a history entry ID")
+			return
+		}
+	}
+
+	entries, err := services.GetDummyProductHistory(r.Context(), uint(productID), before, limit+1)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProductHistory", "database_error")
+		metrics.RecordDetailedError("GetDummyProductHistory", "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product_history", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error retrieving dummy product history")
+		return
+	}
+
+	// Purged products keep their history, so only a product without any entry is missing
+	if len(entries) == 0 && before == 0 {
+		metrics.RecordHandlerError("GetDummyProductHistory", "not_found")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product_history", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Dummy product history not found")
+		return
+	}
+
+	page := models.DummyProductHistoryPage{Items: entries, Limit: limit}
+	if len(entries) > limit {
+		page.Items = entries[:limit]
+		page.NextBefore = page.Items[limit-1].ID
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_dummy_product_history", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Dummy product history retrieved successfully",
+		Data:    page,
+	})
+}
+
+// getDummyProductAsOf responds with a dummy product as it was at a past
+// moment. Point-in-time views are rebuilt from the history and never cached.
+func getDummyProductAsOf(w http.ResponseWriter, r *http.Request, id, rawAsOf, displayCurrency string, selection utils.FieldSelection) {
+	productID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProduct", "invalid_request", "invalid_id")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid dummy product ID")
+		return
+	}
+
+	asOf, err := time.Parse(time.RFC3339Nano, rawAsOf)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProduct", "invalid_request", "invalid_as_of")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "as_of must be an RFC3339 timestamp")
+		return
+	}
+
+	product, err := services.DummyProductAsOf(r.Context(), uint(productID), asOf)
+	if errors.Is(err, services.ErrProductNotFound) {
+		metrics.RecordHandlerError("GetDummyProduct", "not_found")
+		metrics.RecordDetailedError("GetDummyProduct", "not_found", "as_of")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Dummy product did not exist at the requested time")
+		return
+	}
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProduct", "database_error")
+		metrics.RecordDetailedError("GetDummyProduct", "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error reconstructing dummy product")
+		return
+	}
+
+	setDisplayPrice(product, displayCurrency)
+
+	data, err := selection.Project(product, displayPriceKeys(displayCurrency)...)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProduct", "internal_error")
+		metrics.RecordDetailedError("GetDummyProduct", "internal_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error encoding dummy product")
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_dummy_product", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Dummy product reconstructed successfully",
+		Data:    data,
+	})
+}
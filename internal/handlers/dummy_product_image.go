@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/services"
+	"goapi-starter/internal/utils"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead allows for the multipart boundaries and headers on top of
+// the image itself
+const multipartOverhead = 64 << 10
+
+// UploadDummyProductImage stores an image sent as the "image" field of a
+// multipart form. The type is sniffed from the content; JPEG, PNG and GIF are
+// accepted. Thumbnails are generated in the configured sizes and the response
+// carries signed, expiring download URLs.
+func UploadDummyProductImage(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("upload_dummy_product_image", "started").Inc()
+
+	productID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		metrics.RecordHandlerError("UploadDummyProductImage", "invalid_request")
+		metrics.RecordDetailedError("UploadDummyProductImage", "invalid_request", "invalid_id")
+		metrics.BusinessOperations.WithLabelValues("upload_dummy_product_image", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid dummy product ID")
+		return
+	}
+
+	maxBytes := config.AppConfig.Products.ImageMaxBytes
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+multipartOverhead)
+
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondImageTooLarge(w, r, maxBytes)
+			return
+		}
+		metrics.RecordHandlerError("UploadDummyProductImage", "invalid_request")
+		metrics.RecordDetailedError("UploadDummyProductImage", "invalid_request", "missing_file")
+		metrics.BusinessOperations.WithLabelValues("upload_dummy_product_image", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Request must be multipart/form-data with an image field")
+		return
+	}
+	defer file.Close()
+	defer r.MultipartForm.RemoveAll()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		metrics.RecordHandlerError("UploadDummyProductImage", "invalid_request")
+		metrics.RecordDetailedError("UploadDummyProductImage", "invalid_request", "read_error")
+		metrics.BusinessOperations.WithLabelValues("upload_dummy_product_image", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Error reading image")
+		return
+	}
+	if int64(len(data)) > maxBytes {
+		respondImageTooLarge(w, r, maxBytes)
+		return
+	}
+
+	img, err := services.UploadDummyProductImage(r.Context(), uint(productID), header.Filename, data)
+	if err != nil {
+		respondWithImageError(w, r, "UploadDummyProductImage", "upload_dummy_product_image", err)
+		return
+	}
+
+	if err := services.SignDummyProductImage(r.Context(), img); err != nil {
+		respondWithImageError(w, r, "UploadDummyProductImage", "upload_dummy_product_image", err)
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("upload_dummy_product_image", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusCreated, utils.SuccessResponse{
+		Message: "Dummy product image uploaded successfully",
+		Data:    img,
+	})
+}
+
+// GetDummyProductImages lists the images of a product with freshly signed URLs
+func GetDummyProductImages(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_dummy_product_images", "started").Inc()
+
+	productID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		metrics.RecordHandlerError("GetDummyProductImages", "invalid_request")
+		metrics.RecordDetailedError("GetDummyProductImages", "invalid_request", "invalid_id")
+		metrics.BusinessOperations.WithLabelValues("get_dummy_product_images", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid dummy product ID")
+		return
+	}
+
+	images, err := services.ListDummyProductImages(r.Context(), uint(productID))
+	if err != nil {
+		respondWithImageError(w, r, "GetDummyProductImages", "get_dummy_product_images", err)
+		return
+	}
+
+	for i := range images {
+		if err := services.SignDummyProductImage(r.Context(), &images[i]); err != nil {
+			respondWithImageError(w, r, "GetDummyProductImages", "get_dummy_product_images", err)
+			return
+		}
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_dummy_product_images", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Dummy product images retrieved successfully",
+		Data:    images,
+	})
+}
+
+// DeleteDummyProductImage removes an image and its thumbnails
+func DeleteDummyProductImage(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("delete_dummy_product_image", "started").Inc()
+
+	productID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		metrics.RecordHandlerError("DeleteDummyProductImage", "invalid_request")
+		metrics.RecordDetailedError("DeleteDummyProductImage", "invalid_request", "invalid_id")
+		metrics.BusinessOperations.WithLabelValues("delete_dummy_product_image", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid dummy product ID")
+		return
+	}
+
+	imageID := chi.URLParam(r, "imageID")
+	if _, err := uuid.Parse(imageID); err != nil {
+		metrics.RecordHandlerError("DeleteDummyProductImage", "invalid_request")
+		metrics.RecordDetailedError("DeleteDummyProductImage", "invalid_request", "invalid_image_id")
+		metrics.BusinessOperations.WithLabelValues("delete_dummy_product_image", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid image ID")
+		return
+	}
+
+	if err := services.DeleteDummyProductImage(r.Context(), uint(productID), imageID); err != nil {
+		respondWithImageError(w, r, "DeleteDummyProductImage", "delete_dummy_product_image", err)
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("delete_dummy_product_image", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Dummy product image deleted successfully",
+	})
+}
+
+func respondImageTooLarge(w http.ResponseWriter, r *http.Request, maxBytes int64) {
+	metrics.RecordHandlerError("UploadDummyProductImage", "payload_too_large")
+	metrics.BusinessOperations.WithLabelValues("upload_dummy_product_image", "failed").Inc()
+	utils.RespondWithError(w, r, http.StatusRequestEntityTooLarge, "Image must not exceed "+strconv.FormatInt(maxBytes, 10)+" bytes")
+}
+
+// respondWithImageError maps image service errors to HTTP responses
+func respondWithImageError(w http.ResponseWriter, r *http.Request, handler, operation string, err error) {
+	status, errorType, message := http.StatusInternalServerError, "storage_error", "Error processing dummy product image"
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		status, errorType, message = http.StatusNotFound, "not_found", "Dummy product not found"
+	case errors.Is(err, services.ErrImageNotFound):
+		status, errorType, message = http.StatusNotFound, "not_found", "Dummy product image not found"
+	case errors.Is(err, services.ErrUnsupportedImage):
+		status, errorType, message = http.StatusUnsupportedMediaType, "unsupported_media_type", "Image must be a JPEG, PNG or GIF"
+	case errors.Is(err, services.ErrImageTooLarge):
+		status, errorType, message = http.StatusUnprocessableEntity, "validation_error", "Image dimensions exceed the allowed number of pixels"
+	}
+
+	metrics.RecordHandlerError(handler, errorType)
+	metrics.RecordDetailedError(handler, errorType, err.Error())
+	metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+	utils.RespondWithError(w, r, status, message)
+}
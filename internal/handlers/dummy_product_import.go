@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/jobs"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/services"
+	"goapi-starter/internal/utils"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+const (
+	// MaxImportSize bounds the size of an uploaded import file
+	MaxImportSize = 100 << 20
+	// AsyncImportThreshold is the request size above which imports always run in the background
+	AsyncImportThreshold = 5 << 20
+
+	// dummyProductImportJobType identifies import jobs in the job tracker
+	dummyProductImportJobType = "dummy_product_import"
+)
+
+// importFormat determines the import format from the format query parameter
+// or, failing that, from the request's Content-Type
+func importFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return services.ImportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonlines":
+		return services.ImportFormatNDJSON
+	default:
+		return ""
+	}
+}
+
+// ImportDummyProducts bulk-creates dummy products from a CSV or NDJSON body.
+// The body is streamed row by row. Small imports run inline and return the
+// report directly; large ones (or ?async=true) are spooled to disk and run as
+// a background job whose progress can be polled at /api/jobs/{id}.
+func ImportDummyProducts(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("import_dummy_products", "started").Inc()
+
+	format := importFormat(r)
+	if format != services.ImportFormatCSV && format != services.ImportFormatNDJSON {
+		metrics.RecordHandlerError("ImportDummyProducts", "unsupported_media_type")
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusUnsupportedMediaType, "Import body must be text/csv or application/x-ndjson")
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = services.ImportModeBestEffort
+	}
+	if mode != services.ImportModeAtomic && mode != services.ImportModeBestEffort {
+		metrics.RecordHandlerError("ImportDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("ImportDummyProducts", "invalid_request", "invalid_mode")
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "mode must be atomic or best_effort")
+		return
+	}
+
+	async, _ := strconv.ParseBool(r.URL.Query().Get("async"))
+	if r.ContentLength > AsyncImportThreshold {
+		async = true
+	}
+
+	body := http.MaxBytesReader(w, r.Body, MaxImportSize)
+
+	if async {
+		startDummyProductImportJob(w, r, body, format, mode)
+		return
+	}
+
+	rows, err := services.NewDummyProductRowReader(format, body)
+	if err != nil {
+		metrics.RecordHandlerError("ImportDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("ImportDummyProducts", "invalid_request", "invalid_header")
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := services.ImportDummyProducts(r.Context(), rows, format, mode, defaultCurrency(), nil)
+	if report.Imported > 0 {
+		cache.InvalidateDummyProductList()
+	}
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			metrics.RecordHandlerError("ImportDummyProducts", "payload_too_large")
+			metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+			utils.RespondWithError(w, r, http.StatusRequestEntityTooLarge, "Import file is too large")
+			return
+		}
+		metrics.RecordHandlerError("ImportDummyProducts", "import_error")
+		metrics.RecordDetailedError("ImportDummyProducts", "import_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error importing dummy products")
+		return
+	}
+
+	if mode == services.ImportModeAtomic && !report.Committed {
+		metrics.RecordHandlerError("ImportDummyProducts", "validation_error")
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithJSON(w, r, http.StatusUnprocessableEntity, utils.SuccessResponse{
+			Message: "Import rejected, no dummy products were imported",
+			Data:    report,
+		})
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("import_dummy_products", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Dummy products imported",
+		Data:    report,
+	})
+}
+
+// startDummyProductImportJob spools the request body to a temporary file and
+// processes it in the background, so the client does not have to keep the
+// connection open for the whole import
+func startDummyProductImportJob(w http.ResponseWriter, r *http.Request, body io.Reader, format, mode string) {
+	userID, _ := utils.GetUserIDFromContext(r.Context())
+
+	spool, err := os.CreateTemp("", "dummy-product-import-*")
+	if err != nil {
+		metrics.RecordHandlerError("ImportDummyProducts", "internal_error")
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error starting import")
+		return
+	}
+
+	cleanup := func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+
+	if _, err := io.Copy(spool, body); err != nil {
+		cleanup()
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			metrics.RecordHandlerError("ImportDummyProducts", "payload_too_large")
+			metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+			utils.RespondWithError(w, r, http.StatusRequestEntityTooLarge, "Import file is too large")
+			return
+		}
+		metrics.RecordHandlerError("ImportDummyProducts", "invalid_request")
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Error reading import body")
+		return
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		metrics.RecordHandlerError("ImportDummyProducts", "internal_error")
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error starting import")
+		return
+	}
+
+	// Validate the header up front so obviously bad files fail fast
+	rows, err := services.NewDummyProductRowReader(format, spool)
+	if err != nil {
+		cleanup()
+		metrics.RecordHandlerError("ImportDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("ImportDummyProducts", "invalid_request", "invalid_header")
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	job, err := jobs.NewJob(dummyProductImportJobType, userID)
+	if err != nil {
+		cleanup()
+		metrics.RecordHandlerError("ImportDummyProducts", "internal_error")
+		metrics.BusinessOperations.WithLabelValues("import_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error starting import")
+		return
+	}
+
+	go func() {
+		defer cleanup()
+
+		// Keep the request's actor and correlation ID for the change history
+		report, err := services.ImportDummyProducts(context.WithoutCancel(r.Context()), rows, format, mode, defaultCurrency(), func(progress models.DummyProductImportReport) {
+			job.SetProgress(progress)
+		})
+		if report.Imported > 0 {
+			cache.InvalidateDummyProductList()
+		}
+
+		switch {
+		case err != nil:
+			logger.Error().Err(err).Str("job_id", job.ID).Msg("Dummy product import job failed")
+			job.Fail(err, report)
+		case mode == services.ImportModeAtomic && !report.Committed:
+			job.Fail(errors.New("import rejected, no dummy products were imported"), report)
+		default:
+			job.Complete(report)
+		}
+	}()
+
+	metrics.BusinessOperations.WithLabelValues("import_dummy_products", "accepted").Inc()
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	utils.RespondWithJSON(w, r, http.StatusAccepted, utils.SuccessResponse{
+		Message: "Import started",
+		Data:    job,
+	})
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"io"
+	"mime"
+	"net/http"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// MergePatchContentType is the media type for RFC 7396 JSON Merge Patch
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType is the media type for RFC 6902 JSON Patch
+	JSONPatchContentType = "application/json-patch+json"
+
+	// maxPatchBodySize bounds the size of a patch document
+	maxPatchBodySize = 64 << 10
+)
+
+// acceptPatch is advertised when a client sends an unsupported patch format
+var acceptPatch = MergePatchContentType + ", " + JSONPatchContentType
+
+// PatchDummyProduct applies a JSON Merge Patch or JSON Patch document to a
+// dummy product. The patch is applied to the product's JSON representation,
+// so JSON Patch "test" operations can check any field including the version.
+func PatchDummyProduct(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("patch_dummy_product", "started").Inc()
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		metrics.RecordHandlerError("PatchDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("PatchDummyProduct", "invalid_request", "missing_id")
+		metrics.BusinessOperations.WithLabelValues("patch_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Missing dummy product ID")
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != MergePatchContentType && mediaType != JSONPatchContentType) {
+		metrics.RecordHandlerError("PatchDummyProduct", "unsupported_media_type")
+		metrics.BusinessOperations.WithLabelValues("patch_dummy_product", "failed").Inc()
+		w.Header().Set("Accept-Patch", acceptPatch)
+		utils.RespondWithError(w, r, http.StatusUnsupportedMediaType, "Content-Type must be "+acceptPatch)
+		return
+	}
+
+	patchDoc, err := io.ReadAll(io.LimitReader(r.Body, maxPatchBodySize+1))
+	if err != nil || len(patchDoc) > maxPatchBodySize {
+		metrics.RecordHandlerError("PatchDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("PatchDummyProduct", "invalid_request", "body_read_error")
+		metrics.BusinessOperations.WithLabelValues("patch_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	dummyProduct, ok := loadDummyProductForWrite(w, r, "PatchDummyProduct", "patch_dummy_product", id)
+	if !ok {
+		return
+	}
+
+	original, err := json.Marshal(dummyProduct)
+	if err != nil {
+		metrics.RecordHandlerError("PatchDummyProduct", "internal_error")
+		metrics.BusinessOperations.WithLabelValues("patch_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error patching dummy product")
+		return
+	}
+
+	patched, err := applyPatch(mediaType, original, patchDoc)
+	if err != nil {
+		status := http.StatusBadRequest
+		reason := "invalid_patch"
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			status = http.StatusConflict
+			reason = "test_failed"
+		}
+		metrics.RecordHandlerError("PatchDummyProduct", reason)
+		metrics.BusinessOperations.WithLabelValues("patch_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, status, "Unable to apply patch: "+err.Error())
+		return
+	}
+
+	req, err := patchedDummyProductRequest(dummyProduct, patched)
+	if err != nil {
+		metrics.RecordHandlerError("PatchDummyProduct", "invalid_patch")
+		metrics.RecordDetailedError("PatchDummyProduct", "invalid_patch", "patched_document")
+		metrics.BusinessOperations.WithLabelValues("patch_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	req = req.WithDefaultCurrency(defaultCurrency())
+
+	// The patched product must satisfy the same rules as a newly created one
+	if err := utils.ValidateStruct(req); err != nil {
+		metrics.RecordHandlerError("PatchDummyProduct", "validation_error")
+		metrics.RecordDetailedError("PatchDummyProduct", "validation_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("patch_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	saveDummyProductUpdate(w, r, "PatchDummyProduct", "patch_dummy_product", dummyProduct, req)
+}
+
+// applyPatch applies a patch document of the given media type to doc
+func applyPatch(mediaType string, doc, patchDoc []byte) ([]byte, error) {
+	if mediaType == MergePatchContentType {
+		return jsonpatch.MergePatch(doc, patchDoc)
+	}
+
+	patch, err := jsonpatch.DecodePatch(patchDoc)
+	if err != nil {
+		return nil, err
+	}
+	return patch.Apply(doc)
+}
+
+// patchedDummyProductRequest decodes the patched document and extracts the
+// writable fields. Unknown fields and changes to read-only fields are rejected.
+func patchedDummyProductRequest(original models.DummyProduct, patched []byte) (models.DummyProductRequest, error) {
+	var result models.DummyProduct
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return models.DummyProductRequest{}, errors.New("patched document is not a valid dummy product: " + err.Error())
+	}
+
+	if result.ID != original.ID ||
+		result.Version != original.Version ||
+		!result.CreatedAt.Equal(original.CreatedAt) ||
+		!result.UpdatedAt.Equal(original.UpdatedAt) ||
+		result.DeletedAt.Valid != original.DeletedAt.Valid {
+		return models.DummyProductRequest{}, errors.New("id, version, created_at, updated_at and deleted_at are read-only")
+	}
+
+	// Stock is managed through the stock and reservation endpoints
+	if result.StockQuantity != original.StockQuantity ||
+		result.ReservedQuantity != original.ReservedQuantity ||
+		result.LowStockThreshold != original.LowStockThreshold {
+		return models.DummyProductRequest{}, errors.New("stock_quantity, reserved_quantity and low_stock_threshold can only be changed through the stock endpoints")
+	}
+
+	// The owner is fixed, and the embedded owner and category only mirror the
+	// stored rows; a product moves to another category through category_id
+	if !sameStringPtr(result.OwnerID, original.OwnerID) ||
+		!sameJSON(result.Owner, original.Owner) ||
+		!sameJSON(result.Category, original.Category) {
+		return models.DummyProductRequest{}, errors.New("owner_id, owner and category are read-only; change category_id to move the product")
+	}
+
+	// Tags are patched as objects; only their names matter. A tag either keeps
+	// the id and created_at of an existing tag or carries a name alone.
+	existing := make(map[uint]models.Tag, len(original.Tags))
+	for _, tag := range original.Tags {
+		existing[tag.ID] = tag
+	}
+	tags := make([]string, len(result.Tags))
+	for i, tag := range result.Tags {
+		if tag.ID != 0 || !tag.CreatedAt.IsZero() {
+			if prev, ok := existing[tag.ID]; !ok || !tag.CreatedAt.Equal(prev.CreatedAt) {
+				return models.DummyProductRequest{}, errors.New("only the names of tags can be changed")
+			}
+		}
+		tags[i] = tag.Name
+	}
+
+	return models.DummyProductRequest{
+		Name:        result.Name,
+		Description: result.Description,
+		Price:       result.Price,
+		Currency:    result.Currency,
+		CategoryID:  result.CategoryID,
+		Tags:        tags,
+	}, nil
+}
+
+// sameStringPtr reports whether two optional strings hold the same value
+func sameStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sameJSON reports whether two values have the same JSON representation
+func sameJSON(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
@@ -0,0 +1,463 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/money"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultDummyProductPageSize is used when the client does not pass a limit
+	DefaultDummyProductPageSize = 20
+	// MaxDummyProductPageSize caps the number of products returned in one page
+	MaxDummyProductPageSize = 100
+
+	// dummyProductListCachePrefix is the prefix for cached product list pages
+	dummyProductListCachePrefix = "dummy_products:list"
+
+	// maxTagFilters bounds the number of tags in a single list filter
+	maxTagFilters = 10
+)
+
+// dummyProductSortColumns lists the columns clients are allowed to sort by
+var dummyProductSortColumns = map[string]bool{
+	"id":         true,
+	"name":       true,
+	"price":      true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+type productSortField struct {
+	Column string
+	Desc   bool
+}
+
+// productListQuery is the normalized form of the list query string
+type productListQuery struct {
+	Limit         int
+	Cursor        string
+	Sort          []productSortField
+	MinPrice      *money.Decimal
+	MaxPrice      *money.Decimal
+	Currency      string
+	NameContains  string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	CategoryID    *uint    // matches the category and all of its descendants
+	Tags          []string // products must carry every listed tag
+}
+
+// productCursor is the decoded form of an opaque next_cursor value
+type productCursor struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// parseDummyProductListQuery validates and normalizes the list query parameters
+func parseDummyProductListQuery(values url.Values) (*productListQuery, error) {
+	q := &productListQuery{Limit: DefaultDummyProductPageSize}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return nil, errors.New("limit must be a positive integer")
+		}
+		if limit > MaxDummyProductPageSize {
+			limit = MaxDummyProductPageSize
+		}
+		q.Limit = limit
+	}
+
+	sortFields, err := parseDummyProductSort(values.Get("sort"))
+	if err != nil {
+		return nil, err
+	}
+	q.Sort = sortFields
+
+	if q.MinPrice, err = parseOptionalDecimal(values, "min_price"); err != nil {
+		return nil, err
+	}
+	if q.MaxPrice, err = parseOptionalDecimal(values, "max_price"); err != nil {
+		return nil, err
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil && q.MinPrice.Cmp(*q.MaxPrice) > 0 {
+		return nil, errors.New("min_price must not be greater than max_price")
+	}
+
+	// Prices are compared as stored, so price filters are usually combined with a currency
+	if raw := values.Get("currency"); raw != "" {
+		q.Currency = money.NormalizeCurrency(raw)
+		if len(q.Currency) != 3 {
+			return nil, errors.New("currency must be an ISO 4217 code")
+		}
+	}
+
+	q.NameContains = strings.TrimSpace(values.Get("name_contains"))
+
+	if q.CreatedAfter, err = parseOptionalTime(values, "created_after"); err != nil {
+		return nil, err
+	}
+	if q.CreatedBefore, err = parseOptionalTime(values, "created_before"); err != nil {
+		return nil, err
+	}
+
+	if raw := values.Get("category_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || id == 0 {
+			return nil, errors.New("category_id must be a positive integer")
+		}
+		categoryID := uint(id)
+		q.CategoryID = &categoryID
+	}
+
+	if raw := values.Get("tag"); raw != "" {
+		q.Tags = normalizeTagNames(strings.Split(raw, ","))
+		if len(q.Tags) > maxTagFilters {
+			return nil, fmt.Errorf("at most %d tags can be filtered on", maxTagFilters)
+		}
+		sort.Strings(q.Tags)
+	}
+
+	q.Cursor = values.Get("cursor")
+	if q.Cursor != "" {
+		if _, err := q.decodeCursor(); err != nil {
+			return nil, err
+		}
+	}
+
+	return q, nil
+}
+
+// parseDummyProductSort parses a sort expression such as "price,-created_at".
+// The primary key is always appended as a tie-breaker so keyset pagination is stable.
+func parseDummyProductSort(raw string) ([]productSortField, error) {
+	var fields []productSortField
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := productSortField{Column: part}
+		if strings.HasPrefix(part, "-") {
+			field = productSortField{Column: part[1:], Desc: true}
+		}
+
+		if !dummyProductSortColumns[field.Column] {
+			return nil, fmt.Errorf("cannot sort by %q", field.Column)
+		}
+		if seen[field.Column] {
+			continue
+		}
+		seen[field.Column] = true
+		fields = append(fields, field)
+	}
+
+	if !seen["id"] {
+		fields = append(fields, productSortField{Column: "id"})
+	}
+
+	return fields, nil
+}
+
+func parseOptionalDecimal(values url.Values, name string) (*money.Decimal, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := money.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a decimal number", name)
+	}
+	return &v, nil
+}
+
+func parseOptionalTime(values url.Values, name string) (*time.Time, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+// sortColumns returns the columns the sort and the next-page cursor read
+func (q *productListQuery) sortColumns() []string {
+	columns := make([]string, len(q.Sort))
+	for i, f := range q.Sort {
+		columns[i] = f.Column
+	}
+	return columns
+}
+
+// sortKey returns the canonical representation of the sort order
+func (q *productListQuery) sortKey() string {
+	parts := make([]string, len(q.Sort))
+	for i, f := range q.Sort {
+		if f.Desc {
+			parts[i] = "-" + f.Column
+		} else {
+			parts[i] = f.Column
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// normalized returns a canonical string for the query, used to derive cache keys
+func (q *productListQuery) normalized() string {
+	v := url.Values{}
+	v.Set("limit", strconv.Itoa(q.Limit))
+	v.Set("sort", q.sortKey())
+	if q.Cursor != "" {
+		v.Set("cursor", q.Cursor)
+	}
+	if q.MinPrice != nil {
+		v.Set("min_price", q.MinPrice.WithPlaces(money.MaxPlaces).String())
+	}
+	if q.MaxPrice != nil {
+		v.Set("max_price", q.MaxPrice.WithPlaces(money.MaxPlaces).String())
+	}
+	if q.Currency != "" {
+		v.Set("currency", q.Currency)
+	}
+	if q.NameContains != "" {
+		v.Set("name_contains", strings.ToLower(q.NameContains))
+	}
+	if q.CreatedAfter != nil {
+		v.Set("created_after", q.CreatedAfter.UTC().Format(time.RFC3339Nano))
+	}
+	if q.CreatedBefore != nil {
+		v.Set("created_before", q.CreatedBefore.UTC().Format(time.RFC3339Nano))
+	}
+	if q.CategoryID != nil {
+		v.Set("category_id", strconv.FormatUint(uint64(*q.CategoryID), 10))
+	}
+	if len(q.Tags) > 0 {
+		v.Set("tag", strings.Join(q.Tags, ","))
+	}
+	// url.Values.Encode sorts by key, which makes the result canonical
+	return v.Encode()
+}
+
+// cacheKey returns the cache key for this query
+func (q *productListQuery) cacheKey() string {
+	sum := sha256.Sum256([]byte(q.normalized()))
+	return fmt.Sprintf("%s:%s", dummyProductListCachePrefix, hex.EncodeToString(sum[:16]))
+}
+
+// applyFilters adds the WHERE clauses for the filters, ignoring the cursor
+func (q *productListQuery) applyFilters(db *gorm.DB) *gorm.DB {
+	if q.MinPrice != nil {
+		db = db.Where("price >= ?", *q.MinPrice)
+	}
+	if q.MaxPrice != nil {
+		db = db.Where("price <= ?", *q.MaxPrice)
+	}
+	if q.Currency != "" {
+		db = db.Where("currency = ?", q.Currency)
+	}
+	if q.NameContains != "" {
+		db = db.Where("name ILIKE ?", "%"+escapeLike(q.NameContains)+"%")
+	}
+	if q.CreatedAfter != nil {
+		db = db.Where("created_at > ?", *q.CreatedAfter)
+	}
+	if q.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *q.CreatedBefore)
+	}
+	if q.CategoryID != nil {
+		// The materialized path turns the subtree into a single prefix match
+		db = db.Where(`category_id IN (
+			SELECT c.id FROM categories c, categories root
+			WHERE root.id = ? AND c.path LIKE root.path || '%')`, *q.CategoryID)
+	}
+	for _, tag := range q.Tags {
+		db = db.Where(`EXISTS (
+			SELECT 1 FROM dummy_product_tags pt JOIN tags t ON t.id = pt.tag_id
+			WHERE pt.dummy_product_id = dummy_products.id AND t.name = ?)`, tag)
+	}
+	return db
+}
+
+// apply adds filters, the keyset condition for the cursor, ordering and the limit.
+// One extra row is requested so the caller can tell whether another page exists.
+// db should carry the request context so the query stops when the client goes away.
+func (q *productListQuery) apply(db *gorm.DB) (*gorm.DB, error) {
+	db = q.applyFilters(db)
+
+	if q.Cursor != "" {
+		values, err := q.decodeCursor()
+		if err != nil {
+			return nil, err
+		}
+		clause, args := q.keysetCondition(values)
+		db = db.Where(clause, args...)
+	}
+
+	return q.applyOrder(db).Limit(q.Limit + 1), nil
+}
+
+// applyOrder adds the ORDER BY clauses for the sort
+func (q *productListQuery) applyOrder(db *gorm.DB) *gorm.DB {
+	for _, f := range q.Sort {
+		if f.Desc {
+			db = db.Order(f.Column + " DESC")
+		} else {
+			db = db.Order(f.Column + " ASC")
+		}
+	}
+	return db
+}
+
+// keysetCondition builds "rows after the cursor" for a mixed-direction sort, e.g.
+// (a > ?) OR (a = ? AND b < ?) OR (a = ? AND b = ? AND id > ?)
+func (q *productListQuery) keysetCondition(values []interface{}) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	for i, f := range q.Sort {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, q.Sort[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		op := ">"
+		if f.Desc {
+			op = "<"
+		}
+		parts = append(parts, f.Column+" "+op+" ?")
+		args = append(args, values[i])
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// encodeCursor builds the opaque cursor pointing after the given product
+func (q *productListQuery) encodeCursor(p models.DummyProduct) (string, error) {
+	c := productCursor{Sort: q.sortKey()}
+	for _, f := range q.Sort {
+		raw, err := json.Marshal(dummyProductSortValue(p, f.Column))
+		if err != nil {
+			return "", err
+		}
+		c.Values = append(c.Values, raw)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor parses the cursor and converts its values to the sort column types
+func (q *productListQuery) decodeCursor() ([]interface{}, error) {
+	invalid := errors.New("invalid cursor")
+
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var c productCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, invalid
+	}
+	if c.Sort != q.sortKey() || len(c.Values) != len(q.Sort) {
+		return nil, errors.New("cursor does not match the requested sort order")
+	}
+
+	values := make([]interface{}, len(q.Sort))
+	for i, f := range q.Sort {
+		v, err := decodeSortValue(f.Column, c.Values[i])
+		if err != nil {
+			return nil, invalid
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func dummyProductSortValue(p models.DummyProduct, column string) interface{} {
+	switch column {
+	case "name":
+		return p.Name
+	case "price":
+		return p.Price
+	case "created_at":
+		return p.CreatedAt
+	case "updated_at":
+		return p.UpdatedAt
+	default:
+		return p.ID
+	}
+}
+
+func decodeSortValue(column string, raw json.RawMessage) (interface{}, error) {
+	switch column {
+	case "name":
+		var v string
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case "price":
+		var v money.Decimal
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case "created_at", "updated_at":
+		var v time.Time
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	default:
+		var v uint
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in user input
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// estimateDummyProductCount asks the planner for a row estimate of the filtered
+// listing instead of running an exact COUNT(*) over a potentially large table.
+// The EXPLAIN runs on db, so it shares the caller's context.
+func estimateDummyProductCount(db *gorm.DB, q *productListQuery) (int64, error) {
+	stmt := q.applyFilters(db.Session(&gorm.Session{DryRun: true}).Model(&models.DummyProduct{})).
+		Find(&[]models.DummyProduct{}).Statement
+
+	var plan string
+	if err := db.Raw("EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).Row().Scan(&plan); err != nil {
+		return 0, err
+	}
+
+	var explained []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil || len(explained) == 0 {
+		return 0, errors.New("unable to parse query plan")
+	}
+
+	return int64(explained[0].Plan.PlanRows), nil
+}
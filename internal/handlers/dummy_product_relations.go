@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errUnknownCategory is returned when a product references a missing category
+var errUnknownCategory = errors.New("category does not exist")
+
+// preloadDummyProductRelations loads a product's category and tags
+func preloadDummyProductRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Category").Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("tags.name")
+	})
+}
+
+// newDummyProduct builds the product described by a create request, owned by
+// the user making the request
+func newDummyProduct(ctx context.Context, req models.DummyProductRequest) models.DummyProduct {
+	price := req.Money()
+	product := models.DummyProduct{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       price.Amount,
+		Currency:    price.Currency,
+		CategoryID:  req.CategoryID,
+	}
+	if userID, ok := utils.GetUserIDFromContext(ctx); ok {
+		product.OwnerID = &userID
+	}
+	return product
+}
+
+// dummyProductUpdates returns the column updates for a full replacement
+func dummyProductUpdates(req models.DummyProductRequest) map[string]interface{} {
+	price := req.Money()
+	return map[string]interface{}{
+		"name":        req.Name,
+		"description": req.Description,
+		"price":       price.Amount,
+		"currency":    price.Currency,
+		"category_id": req.CategoryID,
+	}
+}
+
+// checkDummyProductCategory verifies that the referenced category exists
+func checkDummyProductCategory(db *gorm.DB, categoryID *uint) error {
+	if categoryID == nil {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(&models.Category{}).Where("id = ?", *categoryID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errUnknownCategory
+	}
+	return nil
+}
+
+// normalizeTagName returns the stored form of a tag name
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTagNames normalizes and de-duplicates tag names, dropping empty ones
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// resolveTags returns the tags with the given names, creating missing ones
+func resolveTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{Name: name}
+	}
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+		Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Conflicting rows are not returned by the insert, so read them all back
+	var stored []models.Tag
+	if err := tx.Where("name IN ?", names).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// setDummyProductTags replaces the tags of a product
+func setDummyProductTags(tx *gorm.DB, productID uint, names []string) error {
+	tags, err := resolveTags(tx, normalizeTagNames(names))
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Where("dummy_product_id = ?", productID).Delete(&models.DummyProductTag{}).Error; err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	links := make([]models.DummyProductTag, len(tags))
+	for i, tag := range tags {
+		links[i] = models.DummyProductTag{DummyProductID: productID, TagID: tag.ID}
+	}
+	return tx.Create(&links).Error
+}
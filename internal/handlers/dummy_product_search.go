@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxSearchTerms bounds the size of the generated tsquery
+	maxSearchTerms = 10
+	// maxSearchQueryLength bounds the raw query string, in characters
+	maxSearchQueryLength = 200
+)
+
+// dummyProductSearchSQL ranks products by full-text relevance and name similarity.
+// Terms are matched as prefixes against the tsvector, and pg_trgm similarity on
+// the name catches typos that full-text search cannot.
+const dummyProductSearchSQL = `
+SELECT p.id, p.name, p.description, p.price, p.currency, p.owner_id, p.category_id,
+	p.stock_quantity, p.reserved_quantity, p.low_stock_threshold, p.version, p.created_at, p.updated_at,
+	ts_rank_cd(p.search_vector, q.terms) + word_similarity(@raw, p.name) AS rank,
+	ts_headline('english', p.name, q.terms, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS name_highlight,
+	ts_headline('english', coalesce(p.description, ''), q.terms,
+		'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS description_snippet
+FROM dummy_products p, (SELECT to_tsquery('english', @terms) AS terms) q
+WHERE p.deleted_at IS NULL
+	AND (p.search_vector @@ q.terms OR p.name % @raw OR @raw <% p.name)
+ORDER BY rank DESC, p.id ASC
+LIMIT @limit`
+
+// buildPrefixTSQuery turns free text into a prefix tsquery such as "wire:* & mou:*".
+// Only letters and digits survive, so the result is always a valid tsquery.
+func buildPrefixTSQuery(query string) string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// SearchDummyProducts performs a ranked full-text search over dummy products
+func SearchDummyProducts(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("search_dummy_products", "started").Inc()
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if runes := []rune(query); len(runes) > maxSearchQueryLength {
+		query = string(runes[:maxSearchQueryLength])
+	}
+
+	terms := buildPrefixTSQuery(query)
+	if terms == "" {
+		metrics.RecordHandlerError("SearchDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("SearchDummyProducts", "invalid_request", "missing_query")
+		metrics.BusinessOperations.WithLabelValues("search_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Search query is required")
+		return
+	}
+
+	limit := DefaultDummyProductPageSize
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			metrics.RecordHandlerError("SearchDummyProducts", "invalid_request")
+			metrics.RecordDetailedError("SearchDummyProducts", "invalid_request", "invalid_limit")
+			metrics.BusinessOperations.WithLabelValues("search_dummy_products", "failed").Inc()
+			utils.RespondWithError(w, r, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if parsed < MaxDummyProductPageSize {
+			limit = parsed
+		} else {
+			limit = MaxDummyProductPageSize
+		}
+	}
+
+	// Try to get from cache first
+	cached, found, err := cache.GetCachedSearchResults(query, limit)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Error retrieving search results from cache")
+		// Continue with database query
+	}
+
+	if found && cached != nil {
+		metrics.BusinessOperations.WithLabelValues("search_dummy_products", "success").Inc()
+		utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+			Message: "Search results retrieved from cache",
+			Data:    cached,
+		})
+		return
+	}
+
+	results := []models.DummyProductSearchResult{}
+	result := database.DB.WithContext(r.Context()).Raw(dummyProductSearchSQL, map[string]interface{}{
+		"raw":   query,
+		"terms": terms,
+		"limit": limit,
+	}).Scan(&results)
+	if result.Error != nil {
+		metrics.RecordHandlerError("SearchDummyProducts", "database_error")
+		metrics.RecordDetailedError("SearchDummyProducts", "database_error", result.Error.Error())
+		metrics.BusinessOperations.WithLabelValues("search_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error searching dummy products")
+		return
+	}
+
+	response := models.DummyProductSearchResponse{
+		Query:   query,
+		Results: results,
+		Limit:   limit,
+	}
+
+	// Store in cache for future requests
+	if err := cache.CacheSearchResults(query, limit, response); err != nil {
+		logger.Warn().Err(err).Msg("Failed to cache search results")
+	}
+
+	metrics.BusinessOperations.WithLabelValues("search_dummy_products", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Search results retrieved successfully",
+		Data:    response,
+	})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// GetDeletedDummyProducts returns a page of soft-deleted dummy products that
+// can still be restored. The trash supports the same filters and cursor as
+// the main listing and is never cached.
+func GetDeletedDummyProducts(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_deleted_dummy_products", "started").Inc()
+
+	query, err := parseDummyProductListQuery(r.URL.Query())
+	if err != nil {
+		metrics.RecordHandlerError("GetDeletedDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("GetDeletedDummyProducts", "invalid_request", "invalid_query")
+		metrics.BusinessOperations.WithLabelValues("get_deleted_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db, err := query.apply(database.DB.WithContext(r.Context()).Unscoped().Model(&models.DummyProduct{}).Where("deleted_at IS NOT NULL"))
+	if err != nil {
+		metrics.RecordHandlerError("GetDeletedDummyProducts", "invalid_request")
+		metrics.RecordDetailedError("GetDeletedDummyProducts", "invalid_request", "invalid_cursor")
+		metrics.BusinessOperations.WithLabelValues("get_deleted_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dummyProducts := []models.DummyProduct{}
+	if result := db.Find(&dummyProducts); result.Error != nil {
+		metrics.RecordHandlerError("GetDeletedDummyProducts", "database_error")
+		metrics.RecordDetailedError("GetDeletedDummyProducts", "database_error", result.Error.Error())
+		metrics.BusinessOperations.WithLabelValues("get_deleted_dummy_products", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error retrieving deleted dummy products")
+		return
+	}
+
+	page := models.DummyProductPage{Items: dummyProducts, Limit: query.Limit}
+	if len(dummyProducts) > query.Limit {
+		page.Items = dummyProducts[:query.Limit]
+		if page.NextCursor, err = query.encodeCursor(page.Items[query.Limit-1]); err != nil {
+			logger.Warn().Err(err).Msg("Failed to encode next page cursor")
+		}
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_deleted_dummy_products", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Deleted dummy products retrieved successfully",
+		Data:    page,
+	})
+}
+
+// RestoreDummyProduct moves a soft-deleted dummy product out of the trash
+func RestoreDummyProduct(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("restore_dummy_product", "started").Inc()
+
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		metrics.RecordHandlerError("RestoreDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("RestoreDummyProduct", "invalid_request", "missing_id")
+		metrics.BusinessOperations.WithLabelValues("restore_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Missing dummy product ID")
+		return
+	}
+
+	var dummyProduct models.DummyProduct
+	if result := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&dummyProduct, id); result.Error != nil {
+		metrics.RecordHandlerError("RestoreDummyProduct", "not_found")
+		metrics.RecordDetailedError("RestoreDummyProduct", "not_found", "id_"+id)
+		metrics.BusinessOperations.WithLabelValues("restore_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Deleted dummy product not found")
+		return
+	}
+
+	// Restoring is a write, so it bumps the version and invalidates old ETags
+	result := database.DB.WithContext(r.Context()).Unscoped().Model(&models.DummyProduct{}).
+		Where("id = ? AND version = ? AND deleted_at IS NOT NULL", dummyProduct.ID, dummyProduct.Version).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		metrics.RecordHandlerError("RestoreDummyProduct", "database_error")
+		metrics.RecordDetailedError("RestoreDummyProduct", "database_error", result.Error.Error())
+		metrics.BusinessOperations.WithLabelValues("restore_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error restoring dummy product")
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		metrics.RecordHandlerError("RestoreDummyProduct", "conflict")
+		metrics.RecordDetailedError("RestoreDummyProduct", "conflict", "concurrent_update")
+		metrics.BusinessOperations.WithLabelValues("restore_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusConflict, "Dummy product was modified concurrently, please retry")
+		return
+	}
+
+	// Get the restored dummy product
+	preloadDummyProductRelations(database.DB).First(&dummyProduct, dummyProduct.ID)
+
+	// Cache the restored product and invalidate the list cache since it reappears there
+	if err := cache.Prime(cache.DummyProductKey(dummyProduct.ID), dummyProduct, config.AppConfig.Redis.CacheTTL, cache.DummyProductTag(dummyProduct.ID)); err != nil {
+		logger.Warn().Err(err).Str("id", id).Msg("Failed to cache restored dummy product")
+	}
+	cache.InvalidateDummyProductList()
+
+	metrics.BusinessOperations.WithLabelValues("restore_dummy_product", "success").Inc()
+	w.Header().Set("ETag", dummyProductETag(dummyProduct))
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Dummy product restored successfully",
+		Data:    dummyProduct,
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/storage"
+	"goapi-starter/internal/utils"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ServeFile streams an object of the local storage driver. It is public, so
+// access is granted by the signature and expiry that Local.SignedURL put in
+// the query string.
+func ServeFile(w http.ResponseWriter, r *http.Request) {
+	local, ok := storage.Blobs.(*storage.Local)
+	if !ok {
+		utils.RespondWithError(w, r, http.StatusNotFound, "Not found")
+		return
+	}
+
+	key := chi.URLParam(r, "*")
+	query := r.URL.Query()
+	if !local.Verify(key, query.Get("expires"), query.Get("signature")) {
+		metrics.RecordHandlerError("ServeFile", "forbidden")
+		utils.RespondWithError(w, r, http.StatusForbidden, "Invalid or expired download link")
+		return
+	}
+
+	body, object, err := local.Get(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		metrics.RecordHandlerError("ServeFile", "not_found")
+		utils.RespondWithError(w, r, http.StatusNotFound, "File not found")
+		return
+	}
+	if err != nil {
+		metrics.RecordHandlerError("ServeFile", "storage_error")
+		metrics.RecordDetailedError("ServeFile", "storage_error", err.Error())
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error reading file")
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
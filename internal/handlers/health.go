@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"goapi-starter/internal/cache"
 	"goapi-starter/internal/database"
 	"goapi-starter/internal/logger"
 	"goapi-starter/internal/utils"
@@ -28,12 +29,19 @@ func HealthCheck(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info().Msg("Database ping successful")
 
+	// The cache is optional: while it is down the service keeps running degraded
+	cacheHealth := cache.Health()
+	if cacheHealth.Status != "up" {
+		logger.Warn().Str("last_error", cacheHealth.LastError).Msg("Service is running without cache")
+	}
+
 	// All checks passed
 	logger.Info().Msg("Service is healthy")
 	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
 		Message: "Service is healthy",
-		Data: map[string]string{
+		Data: map[string]interface{}{
 			"status": "UP",
+			"cache":  cacheHealth,
 		},
 	})
 }
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/services"
+	"goapi-starter/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ReserveDummyProduct holds units of a product for the current user. The
+// reservation expires after ttl_seconds (or the configured default) unless it
+// is confirmed, releasing the units back into stock.
+func ReserveDummyProduct(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("reserve_dummy_product", "started").Inc()
+
+	productID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		metrics.RecordHandlerError("ReserveDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("ReserveDummyProduct", "invalid_request", "invalid_id")
+		metrics.BusinessOperations.WithLabelValues("reserve_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid dummy product ID")
+		return
+	}
+
+	var req models.ReserveStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		metrics.RecordHandlerError("ReserveDummyProduct", "invalid_request")
+		metrics.RecordDetailedError("ReserveDummyProduct", "invalid_request", "json_decode_error")
+		metrics.BusinessOperations.WithLabelValues("reserve_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		metrics.RecordHandlerError("ReserveDummyProduct", "validation_error")
+		metrics.RecordDetailedError("ReserveDummyProduct", "validation_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("reserve_dummy_product", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ttl := config.AppConfig.Products.ReservationTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	userID, _ := utils.GetUserIDFromContext(r.Context())
+	reservation, err := services.ReserveStock(r.Context(), uint(productID), userID, req.Quantity, ttl)
+	if err != nil {
+		respondWithInventoryError(w, r, "ReserveDummyProduct", "reserve_dummy_product", err)
+		return
+	}
+
+	logger.Info().
+		Str("reservation_id", reservation.ID).
+		Uint64("product_id", productID).
+		Int("quantity", reservation.Quantity).
+		Time("expires_at", reservation.ExpiresAt).
+		Msg("Stock reserved")
+
+	metrics.BusinessOperations.WithLabelValues("reserve_dummy_product", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusCreated, utils.SuccessResponse{
+		Message: "Stock reserved successfully",
+		Data:    reservation,
+	})
+}
+
+// UpdateDummyProductStock sets the stock on hand and low-stock threshold of a product
+func UpdateDummyProductStock(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("update_dummy_product_stock", "started").Inc()
+
+	productID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		metrics.RecordHandlerError("UpdateDummyProductStock", "invalid_request")
+		metrics.RecordDetailedError("UpdateDummyProductStock", "invalid_request", "invalid_id")
+		metrics.BusinessOperations.WithLabelValues("update_dummy_product_stock", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid dummy product ID")
+		return
+	}
+
+	var req models.UpdateStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		metrics.RecordHandlerError("UpdateDummyProductStock", "invalid_request")
+		metrics.RecordDetailedError("UpdateDummyProductStock", "invalid_request", "json_decode_error")
+		metrics.BusinessOperations.WithLabelValues("update_dummy_product_stock", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		metrics.RecordHandlerError("UpdateDummyProductStock", "validation_error")
+		metrics.RecordDetailedError("UpdateDummyProductStock", "validation_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("update_dummy_product_stock", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product, err := services.SetStock(r.Context(), uint(productID), req.StockQuantity, req.LowStockThreshold)
+	if err != nil {
+		respondWithInventoryError(w, r, "UpdateDummyProductStock", "update_dummy_product_stock", err)
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("update_dummy_product_stock", "success").Inc()
+	w.Header().Set("ETag", dummyProductETag(*product))
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Dummy product stock updated successfully",
+		Data:    product,
+	})
+}
+
+// GetReservation returns a stock reservation owned by the current user
+func GetReservation(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_reservation", "started").Inc()
+
+	id, ok := reservationID(w, r, "GetReservation", "get_reservation")
+	if !ok {
+		return
+	}
+
+	userID, _ := utils.GetUserIDFromContext(r.Context())
+	reservation, err := services.GetReservation(r.Context(), id, userID)
+	if err != nil {
+		respondWithInventoryError(w, r, "GetReservation", "get_reservation", err)
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_reservation", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Reservation retrieved successfully",
+		Data:    reservation,
+	})
+}
+
+// ConfirmReservation turns a pending reservation into a sale
+func ConfirmReservation(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("confirm_reservation", "started").Inc()
+
+	id, ok := reservationID(w, r, "ConfirmReservation", "confirm_reservation")
+	if !ok {
+		return
+	}
+
+	userID, _ := utils.GetUserIDFromContext(r.Context())
+	reservation, err := services.ConfirmReservation(r.Context(), id, userID)
+	if err != nil {
+		respondWithInventoryError(w, r, "ConfirmReservation", "confirm_reservation", err)
+		return
+	}
+
+	logger.Info().Str("reservation_id", id).Int("quantity", reservation.Quantity).Msg("Reservation confirmed")
+
+	metrics.BusinessOperations.WithLabelValues("confirm_reservation", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Reservation confirmed successfully",
+		Data:    reservation,
+	})
+}
+
+// CancelReservation releases a pending reservation's units back into stock
+func CancelReservation(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("cancel_reservation", "started").Inc()
+
+	id, ok := reservationID(w, r, "CancelReservation", "cancel_reservation")
+	if !ok {
+		return
+	}
+
+	userID, _ := utils.GetUserIDFromContext(r.Context())
+	reservation, err := services.CancelReservation(r.Context(), id, userID)
+	if err != nil {
+		respondWithInventoryError(w, r, "CancelReservation", "cancel_reservation", err)
+		return
+	}
+
+	logger.Info().Str("reservation_id", id).Int("quantity", reservation.Quantity).Msg("Reservation cancelled")
+
+	metrics.BusinessOperations.WithLabelValues("cancel_reservation", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Reservation cancelled successfully",
+		Data:    reservation,
+	})
+}
+
+// reservationID reads and validates the reservation ID from the URL
+func reservationID(w http.ResponseWriter, r *http.Request, handler, operation string) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		metrics.RecordHandlerError(handler, "invalid_request")
+		metrics.RecordDetailedError(handler, "invalid_request", "invalid_id")
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid reservation ID")
+		return "", false
+	}
+	return id, true
+}
+
+// respondWithInventoryError maps inventory service errors to HTTP responses
+func respondWithInventoryError(w http.ResponseWriter, r *http.Request, handler, operation string, err error) {
+	status, errorType, message := http.StatusInternalServerError, "database_error", "Error updating inventory"
+	switch {
+	case errors.Is(err, services.ErrProductNotFound):
+		status, errorType, message = http.StatusNotFound, "not_found", "Dummy product not found"
+	case errors.Is(err, services.ErrReservationNotFound):
+		status, errorType, message = http.StatusNotFound, "not_found", "Reservation not found"
+	case errors.Is(err, services.ErrInsufficientStock):
+		status, errorType, message = http.StatusConflict, "insufficient_stock", "Not enough stock available"
+	case errors.Is(err, services.ErrStockBelowReserved):
+		status, errorType, message = http.StatusConflict, "conflict", err.Error()
+	case errors.Is(err, services.ErrReservationNotPending):
+		status, errorType, message = http.StatusConflict, "conflict", "Reservation is no longer pending"
+	case errors.Is(err, services.ErrReservationExpired):
+		status, errorType, message = http.StatusGone, "expired", "Reservation has expired and its stock was released"
+	}
+
+	metrics.RecordHandlerError(handler, errorType)
+	metrics.RecordDetailedError(handler, errorType, err.Error())
+	metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+	utils.RespondWithError(w, r, status, message)
+}
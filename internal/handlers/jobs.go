@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"goapi-starter/internal/jobs"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/utils"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetJob returns the status and progress of a background job owned by the current user
+func GetJob(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_job", "started").Inc()
+
+	id := chi.URLParam(r, "id")
+	userID, _ := utils.GetUserIDFromContext(r.Context())
+
+	job, found, err := jobs.GetJob(id)
+	if err != nil {
+		logger.Warn().Err(err).Str("job_id", id).Msg("Error retrieving job")
+		metrics.RecordHandlerError("GetJob", "cache_error")
+		metrics.BusinessOperations.WithLabelValues("get_job", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error retrieving job")
+		return
+	}
+
+	// Jobs belonging to other users are reported as missing
+	if !found || job.OwnerID != userID {
+		metrics.RecordHandlerError("GetJob", "not_found")
+		metrics.BusinessOperations.WithLabelValues("get_job", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Job not found")
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_job", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Job retrieved successfully",
+		Data:    job,
+	})
+}
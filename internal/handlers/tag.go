@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// maxTagListSize caps the number of tags returned by GetTags
+const maxTagListSize = 100
+
+// taggedDummyProductsCondition matches the products carrying a tag
+const taggedDummyProductsCondition = "id IN (SELECT dummy_product_id FROM dummy_product_tags WHERE tag_id = ?)"
+
+// taggedDummyProductIDs returns the IDs of the products carrying a tag
+func taggedDummyProductIDs(tagID uint) []uint {
+	var ids []uint
+	database.DB.Model(&models.DummyProductTag{}).Where("tag_id = ?", tagID).Pluck("dummy_product_id", &ids)
+	return ids
+}
+
+// decodeTagRequest decodes, validates and normalizes a tag request body. It
+// writes the error response and returns false when the body is invalid.
+func decodeTagRequest(w http.ResponseWriter, r *http.Request, handler, operation string) (models.TagRequest, bool) {
+	var req models.TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		metrics.RecordHandlerError(handler, "invalid_request")
+		metrics.RecordDetailedError(handler, "invalid_request", "json_decode_error")
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid request body")
+		return req, false
+	}
+
+	req.Name = normalizeTagName(req.Name)
+	if err := utils.ValidateStruct(req); err != nil {
+		metrics.RecordHandlerError(handler, "validation_error")
+		metrics.RecordDetailedError(handler, "validation_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues(operation, "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+
+	return req, true
+}
+
+// GetTags returns tags in alphabetical order, optionally filtered by ?prefix
+func GetTags(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("get_tags", "started").Inc()
+
+	db := database.DB.Model(&models.Tag{})
+	if prefix := normalizeTagName(r.URL.Query().Get("prefix")); prefix != "" {
+		db = db.Where("name LIKE ?", escapeLike(prefix)+"%")
+	}
+
+	tags := []models.Tag{}
+	if result := db.Order("name").Limit(maxTagListSize).Find(&tags); result.Error != nil {
+		metrics.RecordHandlerError("GetTags", "database_error")
+		metrics.RecordDetailedError("GetTags", "database_error", result.Error.Error())
+		metrics.BusinessOperations.WithLabelValues("get_tags", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error retrieving tags")
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("get_tags", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Tags retrieved successfully",
+		Data:    tags,
+	})
+}
+
+// CreateTag creates a tag. Tags are also created implicitly when products are tagged.
+func CreateTag(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("create_tag", "started").Inc()
+
+	req, ok := decodeTagRequest(w, r, "CreateTag", "create_tag")
+	if !ok {
+		return
+	}
+
+	tag := models.Tag{Name: req.Name}
+	if result := database.DB.Create(&tag); result.Error != nil {
+		status, message := http.StatusInternalServerError, "Error creating tag"
+		if strings.Contains(result.Error.Error(), "duplicate") {
+			status, message = http.StatusConflict, "Tag already exists"
+		}
+		metrics.RecordHandlerError("CreateTag", "database_error")
+		metrics.RecordDetailedError("CreateTag", "database_error", result.Error.Error())
+		metrics.BusinessOperations.WithLabelValues("create_tag", "failed").Inc()
+		utils.RespondWithError(w, r, status, message)
+		return
+	}
+
+	metrics.BusinessOperations.WithLabelValues("create_tag", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusCreated, utils.SuccessResponse{
+		Message: "Tag created successfully",
+		Data:    tag,
+	})
+}
+
+// UpdateTag renames a tag on every product carrying it
+func UpdateTag(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("update_tag", "started").Inc()
+
+	id := chi.URLParam(r, "id")
+
+	req, ok := decodeTagRequest(w, r, "UpdateTag", "update_tag")
+	if !ok {
+		return
+	}
+
+	var tag models.Tag
+	if result := database.DB.First(&tag, id); result.Error != nil {
+		metrics.RecordHandlerError("UpdateTag", "not_found")
+		metrics.RecordDetailedError("UpdateTag", "not_found", "id_"+id)
+		metrics.BusinessOperations.WithLabelValues("update_tag", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&tag).Update("name", req.Name).Error; err != nil {
+			return err
+		}
+		return bumpDummyProductVersions(tx, taggedDummyProductsCondition, tag.ID)
+	})
+	if err != nil {
+		status, message := http.StatusInternalServerError, "Error updating tag"
+		if strings.Contains(err.Error(), "duplicate") {
+			status, message = http.StatusConflict, "Tag already exists"
+		}
+		metrics.RecordHandlerError("UpdateTag", "database_error")
+		metrics.RecordDetailedError("UpdateTag", "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("update_tag", "failed").Inc()
+		utils.RespondWithError(w, r, status, message)
+		return
+	}
+
+	cache.InvalidateDummyProducts(taggedDummyProductIDs(tag.ID))
+
+	metrics.BusinessOperations.WithLabelValues("update_tag", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Tag updated successfully",
+		Data:    tag,
+	})
+}
+
+// DeleteTag removes a tag from every product and deletes it
+func DeleteTag(w http.ResponseWriter, r *http.Request) {
+	metrics.BusinessOperations.WithLabelValues("delete_tag", "started").Inc()
+
+	id := chi.URLParam(r, "id")
+
+	var tag models.Tag
+	if result := database.DB.First(&tag, id); result.Error != nil {
+		metrics.RecordHandlerError("DeleteTag", "not_found")
+		metrics.RecordDetailedError("DeleteTag", "not_found", "id_"+id)
+		metrics.BusinessOperations.WithLabelValues("delete_tag", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	// Collect the affected products before the cascade removes the links
+	productIDs := taggedDummyProductIDs(tag.ID)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := bumpDummyProductVersions(tx, taggedDummyProductsCondition, tag.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+	if err != nil {
+		metrics.RecordHandlerError("DeleteTag", "database_error")
+		metrics.RecordDetailedError("DeleteTag", "database_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("delete_tag", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error deleting tag")
+		return
+	}
+
+	cache.InvalidateDummyProducts(productIDs)
+
+	metrics.BusinessOperations.WithLabelValues("delete_tag", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Tag deleted successfully",
+		Data:    nil,
+	})
+}
@@ -14,6 +14,19 @@ import (
 func GetProfile(w http.ResponseWriter, r *http.Request) {
 	metrics.BusinessOperations.WithLabelValues("get_profile", "started").Inc()
 
+	selection, err := utils.ParseFieldSelection(r.URL.Query(), &models.User{})
+	if err != nil {
+		metrics.RecordHandlerError("GetProfile", "invalid_request")
+		metrics.RecordDetailedError("GetProfile", "invalid_request", "invalid_fields")
+		metrics.BusinessOperations.WithLabelValues("get_profile", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if selection.Sparse() {
+		getProfileFields(w, r, selection)
+		return
+	}
+
 	// Try to get user from context (cached)
 	user, found := utils.GetUserFromContext(r.Context())
 
@@ -40,47 +53,67 @@ func GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Try to get from cache directly (fallback)
-	cachedUser, found, err := cache.GetCachedUser(userID)
-	if err == nil && found && cachedUser != nil {
-		logger.Debug().
-			Str("user_id", cachedUser.ID).
-			Str("username", cachedUser.Username).
-			Msg("User retrieved from Redis cache")
+	// Load through the cache so concurrent misses only hit the database once
+	dbUser, err := cache.GetOrLoadUser(userID, func() (models.User, error) {
+		var dbUser models.User
+		err := database.DB.First(&dbUser, "id = ?", userID).Error
+		return dbUser, err
+	})
+	if err != nil {
+		metrics.RecordHandlerError("GetProfile", "user_not_found")
+		metrics.BusinessOperations.WithLabelValues("get_profile", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusNotFound, "User not found")
+		return
+	}
 
-		metrics.BusinessOperations.WithLabelValues("get_profile", "success").Inc()
-		utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
-			Message: "Profile retrieved from Redis cache",
-			Data:    cachedUser,
-		})
+	logger.Debug().
+		Str("user_id", dbUser.ID).
+		Str("username", dbUser.Username).
+		Msg("User retrieved for profile")
+
+	// Don't return the id
+	profile := *dbUser
+	profile.ID = ""
+
+	metrics.BusinessOperations.WithLabelValues("get_profile", "success").Inc()
+	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
+		Message: "Profile retrieved successfully",
+		Data:    profile,
+	})
+}
+
+// getProfileFields returns only the requested profile fields, selecting just
+// their columns instead of going through the full cached user
+func getProfileFields(w http.ResponseWriter, r *http.Request, selection utils.FieldSelection) {
+	userID, ok := utils.GetUserIDFromContext(r.Context())
+	if !ok {
+		metrics.RecordHandlerError("GetProfile", "unauthorized")
+		metrics.BusinessOperations.WithLabelValues("get_profile", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusUnauthorized, "User not authenticated")
 		return
 	}
 
-	// Not in cache, get from database
 	var dbUser models.User
-	if result := database.DB.First(&dbUser, "id = ?", userID); result.Error != nil {
+	result := database.DB.WithContext(r.Context()).Select(selection.Columns("id")).First(&dbUser, "id = ?", userID)
+	if result.Error != nil {
 		metrics.RecordHandlerError("GetProfile", "user_not_found")
 		metrics.BusinessOperations.WithLabelValues("get_profile", "failed").Inc()
 		utils.RespondWithError(w, r, http.StatusNotFound, "User not found")
 		return
 	}
 
-	// Cache the user for future requests
-	if err := cache.CacheUser(dbUser); err != nil {
-		logger.Warn().
-			Err(err).
-			Str("user_id", dbUser.ID).
-			Msg("Failed to cache user data")
-		// Continue even if caching fails
+	data, err := selection.Project(dbUser)
+	if err != nil {
+		metrics.RecordHandlerError("GetProfile", "internal_error")
+		metrics.RecordDetailedError("GetProfile", "internal_error", err.Error())
+		metrics.BusinessOperations.WithLabelValues("get_profile", "failed").Inc()
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error encoding profile")
+		return
 	}
 
-	// Don't return the password & id
-	dbUser.Password = ""
-	dbUser.ID = ""
-
 	metrics.BusinessOperations.WithLabelValues("get_profile", "success").Inc()
 	utils.RespondWithJSON(w, r, http.StatusOK, utils.SuccessResponse{
 		Message: "Profile retrieved from database",
-		Data:    dbUser,
+		Data:    data,
 	})
 }
@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"context"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"time"
+)
+
+// Task is a unit of periodic background work
+type Task func(ctx context.Context) error
+
+// Every runs task once per interval in a background goroutine until ctx is
+// cancelled. Runs never overlap: the next tick is only awaited after the
+// current run has returned.
+//
+// With several instances, only the leader elected for the job runs it; the
+// others skip their ticks. The task's context is cancelled if leadership is
+// lost mid-run, and while the cache is unavailable no instance runs the job.
+// Leadership is not fenced, so the task must be safe if, rarely, a stalled
+// former leader is still finishing a run when the new leader starts one.
+func Every(ctx context.Context, name string, interval time.Duration, task Task) {
+	if !scheduled(name, interval) {
+		return
+	}
+
+	election := cache.NewLocker(nil).Campaign(ctx, "job:"+name, config.AppConfig.Cache.LeaderLeaseTTL)
+	go tick(ctx, name, interval, func() {
+		leaderCtx, leader := election.Leading()
+		if !leader {
+			logger.Debug().Str("job", name).Msg("Skipping background job, another instance is the leader")
+			return
+		}
+		run(leaderCtx, name, task)
+	})
+}
+
+// EveryLocal is like Every, but runs task on every instance. It is meant for
+// work on the instance's own state, such as files on its local disk, which
+// no other instance could do in its place.
+func EveryLocal(ctx context.Context, name string, interval time.Duration, task Task) {
+	if !scheduled(name, interval) {
+		return
+	}
+
+	go tick(ctx, name, interval, func() {
+		run(ctx, name, task)
+	})
+}
+
+// scheduled logs the job being scheduled, or reports false if its interval disables it
+func scheduled(name string, interval time.Duration) bool {
+	if interval <= 0 {
+		logger.Warn().Str("job", name).Msg("Background job disabled, interval must be positive")
+		return false
+	}
+
+	logger.Info().
+		Str("job", name).
+		Dur("interval", interval).
+		Msg("Scheduling background job")
+	return true
+}
+
+// tick calls fn once per interval until ctx is cancelled
+func tick(ctx context.Context, name string, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info().Str("job", name).Msg("Background job stopped")
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// run executes a single job run, recording its outcome and recovering from panics
+func run(ctx context.Context, name string, task Task) {
+	start := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			logger.Error().
+				Str("job", name).
+				Interface("panic", rec).
+				Msg("Background job panicked")
+			metrics.RecordBackgroundJob(name, "panic", time.Since(start))
+		}
+	}()
+
+	if err := task(ctx); err != nil {
+		logger.Error().Err(err).Str("job", name).Msg("Background job failed")
+		metrics.RecordBackgroundJob(name, "failed", time.Since(start))
+		return
+	}
+
+	logger.Debug().
+		Str("job", name).
+		Dur("duration", time.Since(start)).
+		Msg("Background job completed")
+	metrics.RecordBackgroundJob(name, "success", time.Since(start))
+}
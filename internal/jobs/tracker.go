@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/logger"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// JobCachePrefix is the prefix for tracked job state
+	JobCachePrefix = "job"
+	// JobTTL is how long job state is kept for polling after the last update
+	JobTTL = 24 * time.Hour
+)
+
+// Status is the lifecycle state of a tracked job
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Job is a long-running operation that clients poll for progress. Its state
+// lives in the shared cache so any instance can answer a poll.
+type Job struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	OwnerID   string      `json:"owner_id"`
+	Status    Status      `json:"status"`
+	Progress  interface{} `json:"progress,omitempty"`
+	Result    interface{} `json:"result,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+func jobKey(id string) string {
+	return fmt.Sprintf("%s:%s", JobCachePrefix, id)
+}
+
+// NewJob creates and stores a pending job owned by the given user
+func NewJob(jobType, ownerID string) (*Job, error) {
+	now := time.Now()
+	job := &Job{
+		ID:        uuid.New().String(),
+		Type:      jobType,
+		OwnerID:   ownerID,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := job.save(); err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Str("job_id", job.ID).
+		Str("job_type", jobType).
+		Str("owner_id", ownerID).
+		Msg("Job created")
+	return job, nil
+}
+
+// GetJob loads a job by ID
+func GetJob(id string) (*Job, bool, error) {
+	var job Job
+	found, err := cache.Get(jobKey(id), &job)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return &job, true, nil
+}
+
+// SetProgress marks the job as running and records its progress
+func (j *Job) SetProgress(progress interface{}) {
+	j.Status = StatusRunning
+	j.Progress = progress
+	j.saveOrWarn()
+}
+
+// Complete marks the job as successfully finished with the given result
+func (j *Job) Complete(result interface{}) {
+	j.Status = StatusCompleted
+	j.Result = result
+	j.saveOrWarn()
+}
+
+// Fail marks the job as failed, optionally with a partial result
+func (j *Job) Fail(err error, result interface{}) {
+	j.Status = StatusFailed
+	j.Error = err.Error()
+	j.Result = result
+	j.saveOrWarn()
+}
+
+// DecodeResult decodes the job's result into v. Results read back from the
+// cache are generic JSON values, so they are re-encoded into the target type.
+func (j *Job) DecodeResult(v interface{}) error {
+	data, err := json.Marshal(j.Result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (j *Job) save() error {
+	j.UpdatedAt = time.Now()
+	return cache.SetWithTTL(jobKey(j.ID), j, JobTTL)
+}
+
+func (j *Job) saveOrWarn() {
+	if err := j.save(); err != nil {
+		logger.Warn().Err(err).Str("job_id", j.ID).Msg("Failed to store job state")
+	}
+}
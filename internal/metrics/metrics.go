@@ -109,6 +109,58 @@ var (
 		[]string{"result"},
 	)
 
+	// CacheTierResults counts hits and misses per cache tier (l1, l2)
+	CacheTierResults = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "goapi_cache_tier_results_total",
+			Help: "Total number of cache hits/misses per cache tier",
+		},
+		[]string{"tier", "result"},
+	)
+
+	// CacheL1Entries tracks the number of entries in the in-process cache
+	CacheL1Entries = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "goapi_cache_l1_entries",
+			Help: "Number of entries held in the in-process cache",
+		},
+	)
+
+	// CacheAvailable is 1 while the cache backend is reachable and 0 while its circuit breaker is open
+	CacheAvailable = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "goapi_cache_available",
+			Help: "Whether the cache backend is reachable (1) or its circuit breaker is open (0)",
+		},
+	)
+
+	// CacheBreakerTransitions counts circuit breaker state changes
+	CacheBreakerTransitions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "goapi_cache_breaker_transitions_total",
+			Help: "Total number of cache circuit breaker state changes",
+		},
+		[]string{"state"},
+	)
+
+	// CacheDegradedDecisions counts decisions made without the cache, by use (blacklist, rate_limit, data)
+	CacheDegradedDecisions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "goapi_cache_degraded_decisions_total",
+			Help: "Total number of decisions taken by failure policy or local fallback while the cache was unavailable",
+		},
+		[]string{"use", "decision"},
+	)
+
+	// Leadership is 1 for the elections this instance currently leads
+	Leadership = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "goapi_leader",
+			Help: "Whether this instance is the leader (1) of an election, such as a background job",
+		},
+		[]string{"election"},
+	)
+
 	// CacheDuration measures the time taken for cache operations
 	CacheDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -145,6 +197,50 @@ var (
 		},
 		[]string{"limiter_type", "result"},
 	)
+
+	// BackgroundJobRuns counts background job runs by outcome
+	BackgroundJobRuns = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "goapi_background_job_runs_total",
+			Help: "Total number of background job runs",
+		},
+		[]string{"job", "result"},
+	)
+
+	// BackgroundJobDuration measures background job run durations
+	BackgroundJobDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "goapi_background_job_duration_seconds",
+			Help:    "Duration of background job runs in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"job"},
+	)
+
+	// InventoryReservations counts stock reservation operations by outcome
+	InventoryReservations = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "goapi_inventory_reservations_total",
+			Help: "Total number of stock reservation operations",
+		},
+		[]string{"operation", "result"},
+	)
+
+	// InventoryLowStockEvents counts products dropping to or below their low-stock threshold
+	InventoryLowStockEvents = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "goapi_inventory_low_stock_events_total",
+			Help: "Total number of times a product's available stock dropped to its low-stock threshold",
+		},
+	)
+
+	// InventoryLowStockProducts tracks how many products are at or below their low-stock threshold
+	InventoryLowStockProducts = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "goapi_inventory_low_stock_products",
+			Help: "Number of products whose available stock is at or below their low-stock threshold",
+		},
+	)
 )
 
 // RecordRequest records metrics for an HTTP request
@@ -192,6 +288,36 @@ func RecordCacheResult(result string) {
 	CacheResults.WithLabelValues(result).Inc()
 }
 
+// RecordCacheTierResult records a hit or miss in one cache tier
+func RecordCacheTierResult(tier, result string) {
+	CacheTierResults.WithLabelValues(tier, result).Inc()
+}
+
+// RecordCacheBreakerState records the circuit breaker opening or closing
+func RecordCacheBreakerState(open bool) {
+	if open {
+		CacheAvailable.Set(0)
+		CacheBreakerTransitions.WithLabelValues("open").Inc()
+		return
+	}
+	CacheAvailable.Set(1)
+	CacheBreakerTransitions.WithLabelValues("closed").Inc()
+}
+
+// RecordCacheDegradedDecision records a decision taken while the cache was unavailable
+func RecordCacheDegradedDecision(use, decision string) {
+	CacheDegradedDecisions.WithLabelValues(use, decision).Inc()
+}
+
+// RecordLeadership records this instance gaining or losing leadership of an election
+func RecordLeadership(election string, leader bool) {
+	if leader {
+		Leadership.WithLabelValues(election).Set(1)
+		return
+	}
+	Leadership.WithLabelValues(election).Set(0)
+}
+
 // RecordCacheDuration records the duration of a cache operation
 func RecordCacheDuration(operation string, duration time.Duration) {
 	CacheDuration.WithLabelValues(operation).Observe(duration.Seconds())
@@ -211,3 +337,14 @@ func RecordRateLimitCheck(limiterType string) {
 func RecordRateLimitResult(limiterType, result string) {
 	RateLimitResults.WithLabelValues(limiterType, result).Inc()
 }
+
+// RecordBackgroundJob records the outcome and duration of a background job run
+func RecordBackgroundJob(job, result string, duration time.Duration) {
+	BackgroundJobRuns.WithLabelValues(job, result).Inc()
+	BackgroundJobDuration.WithLabelValues(job).Observe(duration.Seconds())
+}
+
+// RecordReservation records the outcome of a stock reservation operation
+func RecordReservation(operation, result string) {
+	InventoryReservations.WithLabelValues(operation, result).Inc()
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/utils"
+	"net/http"
+)
+
+// AdminMiddleware only lets users with the admin role through. It must run
+// after AuthMiddleware. Roles are read through the user cache, so a changed
+// role takes effect once the cached user expires or is invalidated.
+func AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := utils.GetUserIDFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, r, http.StatusUnauthorized, "User not authenticated")
+			return
+		}
+
+		user, err := cache.GetOrLoadUser(userID, func() (models.User, error) {
+			var user models.User
+			err := database.DB.First(&user, "id = ?", userID).Error
+			return user, err
+		})
+		if err != nil || user.Role != models.RoleAdmin {
+			logger.Warn().
+				Err(err).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Str("user_id", userID).
+				Msg("Admin access denied")
+			metrics.RecordHandlerError("AdminMiddleware", "forbidden")
+			utils.RespondWithError(w, r, http.StatusForbidden, "Admin access required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
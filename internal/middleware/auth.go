@@ -46,7 +46,9 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		tokenStr := bearerToken[1]
 
-		// Check if token is blacklisted - CRITICAL CHECK
+		// Check if token is blacklisted - CRITICAL CHECK. While the cache is
+		// down this falls back to the local blacklist, and only errors when
+		// CACHE_BLACKLIST_FAILURE_POLICY is closed.
 		blacklisted, err := cache.IsAccessTokenBlacklisted(tokenStr)
 		if err != nil {
 			logger.Warn().
@@ -55,7 +57,8 @@ func AuthMiddleware(next http.Handler) http.Handler {
 				Str("path", r.URL.Path).
 				Msg("Error checking token blacklist")
 			// If we can't check the blacklist, fail closed for security
-			utils.RespondWithError(w, r, http.StatusUnauthorized, "Authentication error")
+			metrics.RecordHandlerError("AuthMiddleware", "blacklist_unavailable")
+			utils.RespondWithError(w, r, http.StatusServiceUnavailable, "Authentication is temporarily unavailable. Please try again later.")
 			return
 		}
 
@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/utils"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client's idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response that was replayed from a previous request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// IdempotencyKeyTTL is how long a completed response can be replayed
+	IdempotencyKeyTTL = 24 * time.Hour
+	// idempotencyLockTTL bounds how long an in-flight request holds its key
+	// after its instance stops renewing it, so a crashed instance cannot block
+	// the key forever. Running requests renew it every third of the TTL.
+	idempotencyLockTTL = 30 * time.Second
+
+	idempotencyCachePrefix   = "idempotency"
+	maxIdempotencyKeyLength  = 255
+	maxIdempotentRequestSize = 1 << 20
+)
+
+// idempotencyRecord is the state stored for an idempotency key. While the
+// first request is in flight only the fingerprint is set.
+type idempotencyRecord struct {
+	Fingerprint string      `json:"fingerprint"`
+	Completed   bool        `json:"completed"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Headers that describe the original exchange rather than the resource and
+// are therefore not replayed
+var idempotencySkippedHeaders = []string{"Content-Length", "Date", "X-Correlation-Id", "X-Ratelimit-"}
+
+// IdempotencyMiddleware makes POST and PATCH requests carrying an
+// Idempotency-Key safe to retry. The first request's response is stored and
+// replayed for retries with the same key and request. Reusing a key with a
+// different request, or while the first request is still running, is
+// rejected with 409. Server errors are not stored, so they
+// can be retried. Request bodies are buffered to fingerprint them, so the
+// middleware belongs on JSON routes only, not on streaming or multipart ones.
+func IdempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			metrics.RecordHandlerError("IdempotencyMiddleware", "invalid_key")
+			utils.RespondWithError(w, r, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentRequestSize+1))
+		if err != nil {
+			metrics.RecordHandlerError("IdempotencyMiddleware", "invalid_request")
+			utils.RespondWithError(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if len(body) > maxIdempotentRequestSize {
+			metrics.RecordHandlerError("IdempotencyMiddleware", "payload_too_large")
+			utils.RespondWithError(w, r, http.StatusRequestEntityTooLarge, "Idempotency-Key is only supported for request bodies up to 1MB")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped per user so clients cannot collide with each other
+		userID, _ := utils.GetUserIDFromContext(r.Context())
+		cacheKey := fmt.Sprintf("%s:%s:%s", idempotencyCachePrefix, userID, key)
+		fingerprint := requestFingerprint(r, body)
+
+		acquired, err := cache.SetIfNotExists(cacheKey, idempotencyRecord{Fingerprint: fingerprint}, idempotencyLockTTL)
+		if err != nil {
+			// Fail open: without the cache we cannot deduplicate, but the
+			// request itself can still be served
+			logger.Warn().Err(err).Str("idempotency_key", key).Msg("Idempotency check failed")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !acquired {
+			handleIdempotentRetry(w, r, cacheKey, key, fingerprint)
+			return
+		}
+
+		wrapped := captureResponseWriter(w)
+		stored := false
+		stopRenewing := renewIdempotencyLock(cacheKey, key)
+		defer func() {
+			stopRenewing()
+			// Release the key if the handler failed or panicked so the client can retry
+			if !stored {
+				if err := cache.Delete(cacheKey); err != nil {
+					logger.Warn().Err(err).Str("idempotency_key", key).Msg("Failed to release idempotency key")
+				}
+			}
+		}()
+
+		next.ServeHTTP(wrapped, r)
+		stopRenewing()
+
+		if wrapped.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		record := idempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      wrapped.Status(),
+			Header:      replayableHeaders(wrapped.Header()),
+			Body:        wrapped.Body(),
+		}
+		if err := cache.SetWithTTL(cacheKey, record, IdempotencyKeyTTL); err != nil {
+			logger.Warn().Err(err).Str("idempotency_key", key).Msg("Failed to store idempotent response")
+			return
+		}
+		stored = true
+	})
+}
+
+// handleIdempotentRetry answers a request whose key is already taken
+func handleIdempotentRetry(w http.ResponseWriter, r *http.Request, cacheKey, key, fingerprint string) {
+	var record idempotencyRecord
+	found, err := cache.Get(cacheKey, &record)
+	if err != nil {
+		metrics.RecordHandlerError("IdempotencyMiddleware", "cache_error")
+		utils.RespondWithError(w, r, http.StatusInternalServerError, "Error checking Idempotency-Key")
+		return
+	}
+
+	// The first request released the key between our two calls; ask the client to retry
+	if !found {
+		metrics.RecordHandlerError("IdempotencyMiddleware", "in_flight")
+		w.Header().Set("Retry-After", "1")
+		utils.RespondWithError(w, r, http.StatusConflict, "A request with this Idempotency-Key is already in progress")
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		metrics.RecordHandlerError("IdempotencyMiddleware", "key_reused")
+		logger.Warn().Str("idempotency_key", key).Msg("Idempotency-Key reused with a different request")
+		utils.RespondWithError(w, r, http.StatusConflict, "Idempotency-Key was already used with a different request")
+		return
+	}
+
+	if !record.Completed {
+		metrics.RecordHandlerError("IdempotencyMiddleware", "in_flight")
+		w.Header().Set("Retry-After", "1")
+		utils.RespondWithError(w, r, http.StatusConflict, "A request with this Idempotency-Key is already in progress")
+		return
+	}
+
+	logger.Info().Str("idempotency_key", key).Int("status", record.Status).Msg("Replaying idempotent response")
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	w.Write(record.Body)
+}
+
+// renewIdempotencyLock keeps an in-flight request's key from expiring while
+// its handler runs, however long that takes. The returned function stops the
+// renewals and may be called more than once.
+func renewIdempotencyLock(cacheKey, key string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(idempotencyLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if _, err := cache.Extend(cacheKey, idempotencyLockTTL); err != nil {
+				logger.Warn().Err(err).Str("idempotency_key", key).Msg("Failed to renew idempotency key")
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+// requestFingerprint identifies a request by its method, URI including the
+// query, content type and body
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write([]byte(r.Header.Get("Content-Type") + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replayableHeaders copies the response headers that should be replayed
+func replayableHeaders(header http.Header) http.Header {
+	replay := http.Header{}
+	for name, values := range header {
+		skip := false
+		for _, prefix := range idempotencySkippedHeaders {
+			if strings.HasPrefix(name, prefix) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			replay[name] = append([]string(nil), values...)
+		}
+	}
+	return replay
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"goapi-starter/internal/cache"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func useMemoryCache(t *testing.T) {
+	t.Helper()
+	previous := cache.Backend
+	cache.Backend = cache.NewMemoryStore()
+	t.Cleanup(func() {
+		cache.Backend.Close()
+		cache.Backend = previous
+	})
+}
+
+func idempotentRequest(target, contentType, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	r.Header.Set(IdempotencyKeyHeader, "key-1")
+	r.Header.Set("Content-Type", contentType)
+	return r.WithContext(context.WithValue(r.Context(), "userID", "user-1"))
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	useMemoryCache(t)
+
+	var calls int32
+	h := IdempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Location", "/items/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(append(body, byte('0'+n)))
+	}))
+
+	first := serve(h, idempotentRequest("/items", "application/json", `{"a":1}`))
+	second := serve(h, idempotentRequest("/items", "application/json", `{"a":1}`))
+
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" || second.Header().Get("Location") != "/items/1" {
+		t.Errorf("replayed headers = %v", second.Header())
+	}
+}
+
+func TestIdempotencyFingerprintMismatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		ctype  string
+		body   string
+	}{
+		{"body", "/items", "application/json", `{"a":2}`},
+		{"query", "/items?mode=best_effort", "application/json", `{"a":1}`},
+		{"content type", "/items", "text/csv", `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useMemoryCache(t)
+			h := IdempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			}))
+
+			if w := serve(h, idempotentRequest("/items", "application/json", `{"a":1}`)); w.Code != http.StatusCreated {
+				t.Fatalf("first request status = %d", w.Code)
+			}
+			if w := serve(h, idempotentRequest(tt.target, tt.ctype, tt.body)); w.Code != http.StatusConflict || w.Header().Get("Retry-After") != "" {
+				t.Errorf("status = %d, Retry-After %q, want %d without Retry-After", w.Code, w.Header().Get("Retry-After"), http.StatusConflict)
+			}
+		})
+	}
+}
+
+func TestIdempotencyConcurrentRequest(t *testing.T) {
+	useMemoryCache(t)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	h := IdempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- serve(h, idempotentRequest("/items", "application/json", `{}`)) }()
+	<-started
+
+	w := serve(h, idempotentRequest("/items", "application/json", `{}`))
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") == "" {
+		t.Errorf("in-flight retry = %d, Retry-After %q, want 409 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first request status = %d", first.Code)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	useMemoryCache(t)
+
+	var calls int32
+	h := IdempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	if w := serve(h, idempotentRequest("/items", "application/json", `{}`)); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first request status = %d", w.Code)
+	}
+	w := serve(h, idempotentRequest("/items", "application/json", `{}`))
+	if w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("retry after a server error = %d, replayed %q, want a fresh 201", w.Code, w.Header().Get(IdempotentReplayedHeader))
+	}
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2", calls)
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"bytes"
 	"goapi-starter/internal/logger"
 	"net/http"
 	"time"
@@ -11,12 +12,18 @@ type responseWriter struct {
 	status       int
 	wroteHeader  bool
 	bytesWritten int
+	body         *bytes.Buffer // copy of the response body, only set when capturing
 }
 
 func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
 	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
 }
 
+// captureResponseWriter wraps w and keeps a copy of everything written to it
+func captureResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK, body: &bytes.Buffer{}}
+}
+
 func (rw *responseWriter) Status() int {
 	return rw.status
 }
@@ -35,6 +42,9 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	}
 	n, err := rw.ResponseWriter.Write(b)
 	rw.bytesWritten += n
+	if rw.body != nil {
+		rw.body.Write(b[:n])
+	}
 	return n, err
 }
 
@@ -42,6 +52,13 @@ func (rw *responseWriter) BytesWritten() int {
 	return rw.bytesWritten
 }
 
+func (rw *responseWriter) Body() []byte {
+	if rw.body == nil {
+		return nil
+	}
+	return rw.body.Bytes()
+}
+
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -19,7 +19,7 @@ func IPRateLimitMiddleware(next http.Handler) http.Handler {
 
 		allowed, remaining, resetAfter, err := limiter.Allow(clientIP)
 		if err != nil {
-			// On error, we'll allow the request but log the issue
+			// The limiter's failure policy decides whether the request is allowed
 			logger.Warn().
 				Err(err).
 				Str("ip", clientIP).
@@ -32,6 +32,12 @@ func IPRateLimitMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
 		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(int64(resetAfter.Seconds()), 10))
 
+		if !allowed && err != nil {
+			metrics.RecordHandlerError("IPRateLimitMiddleware", "rate_limit_unavailable")
+			utils.RespondWithError(w, r, http.StatusServiceUnavailable, "Rate limiting is temporarily unavailable. Please try again later.")
+			return
+		}
+
 		if !allowed {
 			metrics.RecordHandlerError("IPRateLimitMiddleware", "rate_limited")
 			logger.Warn().
@@ -66,7 +72,7 @@ func UserRateLimitMiddleware(next http.Handler) http.Handler {
 
 		allowed, remaining, resetAfter, err := limiter.Allow(userID)
 		if err != nil {
-			// On error, we'll allow the request but log the issue
+			// The limiter's failure policy decides whether the request is allowed
 			logger.Warn().
 				Err(err).
 				Str("user_id", userID).
@@ -79,6 +85,12 @@ func UserRateLimitMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
 		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(int64(resetAfter.Seconds()), 10))
 
+		if !allowed && err != nil {
+			metrics.RecordHandlerError("UserRateLimitMiddleware", "rate_limit_unavailable")
+			utils.RespondWithError(w, r, http.StatusServiceUnavailable, "Rate limiting is temporarily unavailable. Please try again later.")
+			return
+		}
+
 		if !allowed {
 			metrics.RecordHandlerError("UserRateLimitMiddleware", "rate_limited")
 			logger.Warn().
@@ -105,7 +117,7 @@ func AuthRateLimitMiddleware(next http.Handler) http.Handler {
 
 		allowed, remaining, resetAfter, err := limiter.Allow(clientIP)
 		if err != nil {
-			// On error, we'll allow the request but log the issue
+			// The limiter's failure policy decides whether the request is allowed
 			logger.Warn().
 				Err(err).
 				Str("ip", clientIP).
@@ -118,6 +130,12 @@ func AuthRateLimitMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
 		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(int64(resetAfter.Seconds()), 10))
 
+		if !allowed && err != nil {
+			metrics.RecordHandlerError("AuthRateLimitMiddleware", "rate_limit_unavailable")
+			utils.RespondWithError(w, r, http.StatusServiceUnavailable, "Rate limiting is temporarily unavailable. Please try again later.")
+			return
+		}
+
 		if !allowed {
 			metrics.RecordHandlerError("AuthRateLimitMiddleware", "rate_limited")
 			logger.Warn().
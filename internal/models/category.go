@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Category is a node in the product category tree. Path is the materialized
+// path of ancestor IDs including the category itself, e.g. "/1/4/9/", so a
+// whole subtree can be matched with a single prefix comparison.
+type Category struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:100;not null"`
+	Slug      string    `json:"slug" gorm:"size:100;not null"`
+	ParentID  *uint     `json:"parent_id"`
+	Path      string    `json:"path" gorm:"not null;index"`
+	Depth     int       `json:"depth" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CategoryRequest is used for creating or updating a category
+type CategoryRequest struct {
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Slug     string `json:"slug" validate:"omitempty,max=100"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// Tag is a free-form label attached to products. Names are stored lowercased.
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:50;not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagRequest is used for creating or renaming a tag
+type TagRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=50"`
+}
+
+// DummyProductTag is the join table between dummy products and tags
+type DummyProductTag struct {
+	DummyProductID uint `gorm:"primaryKey"`
+	TagID          uint `gorm:"primaryKey"`
+}
@@ -1,29 +1,134 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"goapi-starter/internal/money"
 	"time"
+
+	"gorm.io/gorm"
 )
 
-// DummyProduct represents a dummy product in the system
+// DummyProduct represents a dummy product in the system. Price is an exact
+// decimal in Currency, stored as numeric. StockQuantity is the stock on hand,
+// of which ReservedQuantity is held by pending reservations. OwnerID is the
+// user who created the product. DisplayPrice is only set when a response asks
+// for a conversion and is never stored.
 type DummyProduct struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	Name        string    `json:"name" gorm:"size:100;not null"`
-	Description string    `json:"description" gorm:"size:500"`
-	Price       float64   `json:"price" gorm:"not null"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	Name              string         `json:"name" gorm:"size:100;not null"`
+	Description       string         `json:"description" gorm:"size:500"`
+	Price             money.Decimal  `json:"price" gorm:"type:numeric;not null;index"`
+	Currency          string         `json:"currency" gorm:"size:3;not null;default:USD"`
+	DisplayPrice      *money.Money   `json:"display_price,omitempty" gorm:"-"`
+	OwnerID           *string        `json:"owner_id" gorm:"type:uuid;index"`
+	Owner             *UserSummary   `json:"owner,omitempty" gorm:"foreignKey:OwnerID"`
+	CategoryID        *uint          `json:"category_id" gorm:"index"`
+	Category          *Category      `json:"category,omitempty"`
+	Tags              []Tag          `json:"tags,omitempty" gorm:"many2many:dummy_product_tags"`
+	StockQuantity     int            `json:"stock_quantity" gorm:"not null;default:0"`
+	ReservedQuantity  int            `json:"reserved_quantity" gorm:"not null;default:0"`
+	LowStockThreshold int            `json:"low_stock_threshold" gorm:"not null;default:5"`
+	Version           uint           `json:"version" gorm:"not null;default:1"`
+	CreatedAt         time.Time      `json:"created_at" gorm:"index"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
 
-// DummyProductRequest is used for creating or updating a dummy product
+// DummyProductRequest is used for creating or updating a dummy product. The
+// price accepts a decimal string such as "19.99"; a missing currency is filled
+// in by WithDefaultCurrency.
 type DummyProductRequest struct {
-	Name        string  `json:"name" validate:"required,min=3,max=100"`
-	Description string  `json:"description" validate:"max=500"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
+	Name        string        `json:"name" validate:"required,min=3,max=100"`
+	Description string        `json:"description" validate:"max=500"`
+	Price       money.Decimal `json:"price" validate:"required,gt=0"`
+	Currency    string        `json:"currency" validate:"omitempty,iso4217"`
+	CategoryID  *uint         `json:"category_id"`
+	Tags        []string      `json:"tags" validate:"max=20,dive,min=1,max=50"`
+}
+
+// WithDefaultCurrency returns the request with currency set when it has none
+func (r DummyProductRequest) WithDefaultCurrency(currency string) DummyProductRequest {
+	if money.NormalizeCurrency(r.Currency) == "" {
+		r.Currency = currency
+	}
+	return r
+}
+
+// Money returns the price in its currency, padded to the currency's minor unit
+func (r DummyProductRequest) Money() money.Money {
+	currency := money.NormalizeCurrency(r.Currency)
+	return money.Money{
+		Amount:   r.Price.WithPlaces(money.MinorUnits(currency)),
+		Currency: currency,
+	}
+}
+
+// Validate checks that the price has no more decimal places than its currency allows
+func (r DummyProductRequest) Validate() error {
+	m := r.Money()
+	if !r.Price.FitsPlaces(money.MinorUnits(m.Currency)) {
+		if places := money.MinorUnits(m.Currency); places > 0 {
+			return fmt.Errorf("price must have at most %d decimal places for %s", places, m.Currency)
+		}
+		return errors.New("price must be a whole number for " + m.Currency)
+	}
+	return nil
+}
+
+// DummyProductPage is a single page of a dummy product listing
+type DummyProductPage struct {
+	Items         []DummyProduct `json:"items"`
+	NextCursor    string         `json:"next_cursor,omitempty"`
+	Limit         int            `json:"limit"`
+	TotalEstimate int64          `json:"total_estimate,omitempty"`
+}
+
+// DummyProductSearchResult is a dummy product matched by a full-text search
+type DummyProductSearchResult struct {
+	DummyProduct
+	Rank               float64 `json:"rank"`
+	NameHighlight      string  `json:"name_highlight"`
+	DescriptionSnippet string  `json:"description_snippet"`
+}
+
+// DummyProductSearchResponse wraps the ranked results of a search
+type DummyProductSearchResponse struct {
+	Query   string                     `json:"query"`
+	Results []DummyProductSearchResult `json:"results"`
+	Limit   int                        `json:"limit"`
+}
+
+// DummyProductBatchOperation is a single create, update or delete in a batch.
+// Version, when set, must match the stored version for updates and deletes.
+type DummyProductBatchOperation struct {
+	Op      string               `json:"op"`
+	ID      uint                 `json:"id,omitempty"`
+	Version *uint                `json:"version,omitempty"`
+	Product *DummyProductRequest `json:"product,omitempty"`
+}
+
+// DummyProductBatchRequest is a list of operations executed in one request
+type DummyProductBatchRequest struct {
+	Mode       string                       `json:"mode" validate:"omitempty,oneof=atomic partial"`
+	Operations []DummyProductBatchOperation `json:"operations" validate:"required,min=1,max=500"`
+}
+
+// DummyProductBatchResult is the outcome of a single batch operation
+type DummyProductBatchResult struct {
+	Index   int           `json:"index"`
+	Op      string        `json:"op"`
+	ID      uint          `json:"id,omitempty"`
+	Status  int           `json:"status"`
+	Error   string        `json:"error,omitempty"`
+	Product *DummyProduct `json:"product,omitempty"`
 }
 
-// UpdateDummyProductRequest is used for partial updates to a dummy product
-type UpdateDummyProductRequest struct {
-	Name        *string  `json:"name" validate:"omitempty,min=3,max=100"`
-	Description *string  `json:"description" validate:"omitempty,max=500"`
-	Price       *float64 `json:"price" validate:"omitempty,gt=0"`
+// DummyProductBatchResponse summarizes a batch
+type DummyProductBatchResponse struct {
+	Mode      string                    `json:"mode"`
+	Succeeded int                       `json:"succeeded"`
+	Failed    int                       `json:"failed"`
+	Committed bool                      `json:"committed"`
+	Results   []DummyProductBatchResult `json:"results"`
 }
@@ -0,0 +1,10 @@
+package models
+
+// DummyProductExportResult describes a finished asynchronous export
+type DummyProductExportResult struct {
+	Format      string `json:"format"`
+	Rows        int    `json:"rows"`
+	Size        int64  `json:"size"`
+	FileName    string `json:"file_name"`
+	DownloadURL string `json:"download_url"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// History actions
+const (
+	HistoryActionBaseline = "baseline"
+	HistoryActionCreate   = "create"
+	HistoryActionUpdate   = "update"
+	HistoryActionDelete   = "delete"
+	HistoryActionRestore  = "restore"
+	HistoryActionPurge    = "purge"
+)
+
+// FieldChange is the value of a column before and after a change
+type FieldChange struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
+
+// FieldChanges maps column names to their change, stored as jsonb
+type FieldChanges map[string]FieldChange
+
+// Value encodes the changes as JSON
+func (c FieldChanges) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan decodes a jsonb column
+func (c *FieldChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return fmt.Errorf("cannot scan %T into FieldChanges", value)
+	}
+}
+
+// DummyProductHistory is a recorded change to a dummy product. Entries are
+// written by a database trigger; ActorID is empty for changes made by
+// background jobs.
+type DummyProductHistory struct {
+	ID             uint64       `json:"id" gorm:"primaryKey"`
+	DummyProductID uint         `json:"dummy_product_id"`
+	Action         string       `json:"action"`
+	Version        uint         `json:"version"`
+	ActorID        *string      `json:"actor_id"`
+	CorrelationID  *string      `json:"correlation_id,omitempty"`
+	Changes        FieldChanges `json:"changes" gorm:"type:jsonb"`
+	ChangedAt      time.Time    `json:"changed_at"`
+}
+
+// TableName keeps the history table name singular like its trigger
+func (DummyProductHistory) TableName() string {
+	return "dummy_product_history"
+}
+
+// DummyProductHistoryPage is a page of history entries, newest first
+type DummyProductHistoryPage struct {
+	Items      []DummyProductHistory `json:"items"`
+	NextBefore uint64                `json:"next_before,omitempty"`
+	Limit      int                   `json:"limit"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DummyProductImage is an image uploaded for a dummy product. The original and
+// its thumbnails live in blob storage under the image's key prefix.
+type DummyProductImage struct {
+	ID             string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	DummyProductID uint      `json:"dummy_product_id" gorm:"not null;index"`
+	Filename       string    `json:"filename" gorm:"size:255"`
+	ContentType    string    `json:"content_type" gorm:"size:50;not null"`
+	Extension      string    `json:"-" gorm:"size:10;not null"`
+	Size           int64     `json:"size" gorm:"not null"`
+	Width          int       `json:"width" gorm:"not null"`
+	Height         int       `json:"height" gorm:"not null"`
+	ThumbnailSizes string    `json:"-" gorm:"size:100"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// URLs holds signed download URLs of the original and each thumbnail
+	URLs      map[string]string `json:"urls,omitempty" gorm:"-"`
+	ExpiresAt *time.Time        `json:"urls_expire_at,omitempty" gorm:"-"`
+}
+
+// KeyPrefix is the storage prefix shared by the image's blobs
+func (i DummyProductImage) KeyPrefix() string {
+	return fmt.Sprintf("products/%d/images/%s/", i.DummyProductID, i.ID)
+}
+
+// OriginalKey is the storage key of the uploaded image
+func (i DummyProductImage) OriginalKey() string {
+	return i.KeyPrefix() + "original" + i.Extension
+}
+
+// ThumbnailKey is the storage key of the thumbnail with the given longest edge
+func (i DummyProductImage) ThumbnailKey(size int) string {
+	return i.KeyPrefix() + "thumb_" + strconv.Itoa(size) + ".jpg"
+}
+
+// Thumbnails returns the longest edge of each stored thumbnail
+func (i DummyProductImage) Thumbnails() []int {
+	var sizes []int
+	for _, part := range strings.Split(i.ThumbnailSizes, ",") {
+		if size, err := strconv.Atoi(part); err == nil {
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes
+}
+
+// Keys returns the storage keys of the original and all thumbnails
+func (i DummyProductImage) Keys() []string {
+	keys := []string{i.OriginalKey()}
+	for _, size := range i.Thumbnails() {
+		keys = append(keys, i.ThumbnailKey(size))
+	}
+	return keys
+}
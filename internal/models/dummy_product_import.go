@@ -0,0 +1,19 @@
+package models
+
+// DummyProductImportError describes a row that could not be imported
+type DummyProductImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// DummyProductImportReport summarizes the outcome of a bulk import
+type DummyProductImportReport struct {
+	Format          string                    `json:"format"`
+	Mode            string                    `json:"mode"`
+	Processed       int                       `json:"processed"`
+	Imported        int                       `json:"imported"`
+	Failed          int                       `json:"failed"`
+	Committed       bool                      `json:"committed"`
+	Errors          []DummyProductImportError `json:"errors"`
+	ErrorsTruncated bool                      `json:"errors_truncated,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Reservation statuses
+const (
+	ReservationPending   = "pending"
+	ReservationConfirmed = "confirmed"
+	ReservationCancelled = "cancelled"
+	ReservationExpired   = "expired"
+)
+
+// StockReservation holds units of a product for a user until it is confirmed,
+// cancelled or expires
+type StockReservation struct {
+	ID             string    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	DummyProductID uint      `json:"dummy_product_id" gorm:"not null;index"`
+	UserID         string    `json:"user_id" gorm:"type:uuid;not null"`
+	Quantity       int       `json:"quantity" gorm:"not null"`
+	Status         string    `json:"status" gorm:"size:20;not null"`
+	ExpiresAt      time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ReserveStockRequest is used for reserving units of a product
+type ReserveStockRequest struct {
+	Quantity   int `json:"quantity" validate:"required,gt=0,lte=10000"`
+	TTLSeconds int `json:"ttl_seconds" validate:"omitempty,gte=30,lte=86400"`
+}
+
+// UpdateStockRequest sets the stock on hand and the low-stock threshold of a product
+type UpdateStockRequest struct {
+	StockQuantity     int  `json:"stock_quantity" validate:"gte=0"`
+	LowStockThreshold *int `json:"low_stock_threshold" validate:"omitempty,gte=0"`
+}
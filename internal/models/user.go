@@ -6,12 +6,31 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	// RoleUser is the role of every new user
+	RoleUser = "user"
+	// RoleAdmin grants access to the administration endpoints
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID        string         `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	Username  string         `json:"username" gorm:"uniqueIndex;not null"`
 	Email     string         `json:"email" gorm:"uniqueIndex;not null"`
 	Password  string         `json:"-" gorm:"not null"`
+	Role      string         `json:"role" gorm:"not null;default:user"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
+
+// UserSummary is the public view of a user embedded in other resources
+type UserSummary struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// TableName maps UserSummary onto the users table
+func (UserSummary) TableName() string {
+	return "users"
+}
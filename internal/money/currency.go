@@ -0,0 +1,109 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// defaultMinorUnits is the number of fraction digits of most ISO 4217 currencies
+const defaultMinorUnits = 2
+
+// currencyMinorUnits lists the ISO 4217 currencies whose minor unit is not
+// two digits
+var currencyMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// ErrUnknownRate is returned when no exchange rate is configured for a currency
+var ErrUnknownRate = errors.New("no exchange rate configured")
+
+// NormalizeCurrency returns the canonical upper-case form of a currency code
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// MinorUnits returns the number of fraction digits used by a currency
+func MinorUnits(currency string) int {
+	if places, ok := currencyMinorUnits[NormalizeCurrency(currency)]; ok {
+		return places
+	}
+	return defaultMinorUnits
+}
+
+// Money is an amount in a specific currency
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// Rates converts between currencies using a locally configured table of
+// exchange rates, each expressed as units of that currency per one unit of Base
+type Rates struct {
+	Base    string
+	PerBase map[string]*big.Rat
+}
+
+// rate returns the units of currency per one unit of the base currency
+func (r Rates) rate(currency string) (*big.Rat, error) {
+	currency = NormalizeCurrency(currency)
+	if currency == NormalizeCurrency(r.Base) {
+		return big.NewRat(1, 1), nil
+	}
+	if rate, ok := r.PerBase[currency]; ok && rate.Sign() > 0 {
+		return rate, nil
+	}
+	return nil, fmt.Errorf("%w for %s", ErrUnknownRate, currency)
+}
+
+// Convert converts an amount between currencies, rounding half away from zero
+// to the minor unit of the target currency
+func (r Rates) Convert(amount Decimal, from, to string) (Money, error) {
+	to = NormalizeCurrency(to)
+	places := MinorUnits(to)
+
+	fromRate, err := r.rate(from)
+	if err != nil {
+		return Money{}, err
+	}
+	toRate, err := r.rate(to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// units * toRate / fromRate, computed exactly and rounded once
+	value := new(big.Rat).SetFrac64(amount.units, unitsPerWhole)
+	value.Mul(value, toRate)
+	value.Quo(value, fromRate)
+
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt64(pow10(places)))
+	minor := roundHalfAwayFromZero(scaled)
+	if !minor.IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+
+	converted, err := NewFromMinor(minor.Int64(), places)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: converted, Currency: to}, nil
+}
+
+// roundHalfAwayFromZero rounds a rational to the nearest integer
+func roundHalfAwayFromZero(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
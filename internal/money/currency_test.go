@@ -0,0 +1,53 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestRatesConvert(t *testing.T) {
+	rates := Rates{Base: "USD", PerBase: map[string]*big.Rat{
+		"EUR": big.NewRat(92, 100),
+		"JPY": big.NewRat(15050, 100),
+		"KWD": big.NewRat(307, 1000),
+	}}
+
+	tests := []struct {
+		amount   string
+		from, to string
+		want     string
+	}{
+		{"10.00", "USD", "EUR", "9.20"},
+		{"9.20", "eur", "usd", "10.00"},
+		{"19.99", "USD", "JPY", "3008"},
+		{"1", "EUR", "KWD", "0.334"},
+		{"-0.05", "USD", "EUR", "-0.05"},
+		{"0.01", "USD", "EUR", "0.01"},
+		{"12.5", "USD", "USD", "12.50"},
+	}
+
+	for _, tt := range tests {
+		got, err := rates.Convert(MustParse(tt.amount), tt.from, tt.to)
+		if err != nil {
+			t.Errorf("Convert(%s %s to %s) error = %v", tt.amount, tt.from, tt.to, err)
+			continue
+		}
+		if got.Amount.String() != tt.want || got.Currency != NormalizeCurrency(tt.to) {
+			t.Errorf("Convert(%s %s to %s) = %s %s, want %s %s", tt.amount, tt.from, tt.to,
+				got.Amount, got.Currency, tt.want, NormalizeCurrency(tt.to))
+		}
+	}
+
+	if _, err := rates.Convert(MustParse("1"), "USD", "GBP"); !errors.Is(err, ErrUnknownRate) {
+		t.Errorf("Convert to an unknown currency error = %v, want ErrUnknownRate", err)
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	for currency, want := range map[string]int{"USD": 2, "eur": 2, "JPY": 0, " kwd ": 3, "CLF": 4} {
+		if got := MinorUnits(currency); got != want {
+			t.Errorf("MinorUnits(%q) = %d, want %d", currency, got, want)
+		}
+	}
+}
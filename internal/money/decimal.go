@@ -0,0 +1,299 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxPlaces is the largest number of fraction digits a Decimal can hold,
+// enough for every ISO 4217 minor unit
+const MaxPlaces = 4
+
+// unitsPerWhole is the number of internal units in one whole currency unit
+const unitsPerWhole = 10000
+
+var (
+	// ErrInvalidDecimal is returned for text that is not a plain decimal number
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	// ErrTooManyPlaces is returned for values with more than MaxPlaces significant fraction digits
+	ErrTooManyPlaces = fmt.Errorf("decimal has more than %d fraction digits", MaxPlaces)
+	// ErrOutOfRange is returned for values that do not fit in a Decimal
+	ErrOutOfRange = errors.New("decimal out of range")
+)
+
+// Decimal is an exact fixed-point number for monetary amounts. It keeps the
+// number of fraction digits it was created with, so "12.50" round-trips as
+// "12.50" through JSON and Postgres numeric columns.
+type Decimal struct {
+	units  int64 // value in 1/10^MaxPlaces
+	places uint8 // fraction digits shown by String
+}
+
+// Zero is the zero Decimal
+var Zero = Decimal{}
+
+// NewFromMinor builds a Decimal from an amount in minor units, e.g. 1250 cents
+// with two places is 12.50
+func NewFromMinor(minor int64, places int) (Decimal, error) {
+	if places < 0 || places > MaxPlaces {
+		return Zero, ErrTooManyPlaces
+	}
+	factor := pow10(MaxPlaces - places)
+	if minor > math.MaxInt64/factor || minor < math.MinInt64/factor {
+		return Zero, ErrOutOfRange
+	}
+	return Decimal{units: minor * factor, places: uint8(places)}, nil
+}
+
+// Parse reads a plain decimal such as "12", "-0.5" or "1999.99". Exponents,
+// thousands separators and more than MaxPlaces fraction digits are rejected;
+// trailing zeros beyond MaxPlaces are accepted.
+func Parse(s string) (Decimal, error) {
+	return parse(s, false)
+}
+
+// parse implements Parse. With round set, digits beyond MaxPlaces are rounded
+// half away from zero instead of rejected.
+func parse(s string, round bool) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, ErrInvalidDecimal
+	}
+
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" && frac == "" || hasPoint && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return Zero, ErrInvalidDecimal
+	}
+
+	roundUp := false
+	if trimmed := strings.TrimRight(frac, "0"); len(trimmed) > MaxPlaces {
+		if !round {
+			return Zero, ErrTooManyPlaces
+		}
+		roundUp = frac[MaxPlaces] >= '5'
+	}
+	places := len(frac)
+	if places > MaxPlaces {
+		places = MaxPlaces
+		frac = frac[:MaxPlaces]
+	}
+
+	var units int64
+	if whole != "" {
+		w, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil || w > math.MaxInt64/unitsPerWhole {
+			return Zero, ErrOutOfRange
+		}
+		units = w * unitsPerWhole
+	}
+	if frac != "" {
+		f, _ := strconv.ParseInt(frac, 10, 64)
+		units += f * pow10(MaxPlaces-len(frac))
+	}
+	if roundUp {
+		units++
+	}
+	if units < 0 {
+		return Zero, ErrOutOfRange
+	}
+
+	if neg {
+		units = -units
+	}
+	return Decimal{units: units, places: uint8(places)}, nil
+}
+
+// MustParse is like Parse but panics on invalid input
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// String renders the decimal with its number of fraction digits
+func (d Decimal) String() string {
+	units := d.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	whole := strconv.FormatInt(units/unitsPerWhole, 10)
+	if d.places == 0 {
+		return sign + whole
+	}
+	frac := fmt.Sprintf("%0*d", MaxPlaces, units%unitsPerWhole)
+	return sign + whole + "." + frac[:d.places]
+}
+
+// Sign returns -1, 0 or 1
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Cmp compares two decimals by value, ignoring their number of places
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.units < other.units:
+		return -1
+	case d.units > other.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Places returns the number of fraction digits the decimal is shown with
+func (d Decimal) Places() int {
+	return int(d.places)
+}
+
+// FitsPlaces reports whether the value has no significant digits beyond places
+func (d Decimal) FitsPlaces(places int) bool {
+	if places >= MaxPlaces {
+		return true
+	}
+	return d.units%pow10(MaxPlaces-places) == 0
+}
+
+// WithPlaces returns the decimal rounded half away from zero to places
+// fraction digits
+func (d Decimal) WithPlaces(places int) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if places > MaxPlaces {
+		places = MaxPlaces
+	}
+
+	step := pow10(MaxPlaces - places)
+	units := d.units / step * step
+	if rem := d.units % step; rem*2 >= step {
+		units += step
+	} else if rem*2 <= -step {
+		units -= step
+	}
+	return Decimal{units: units, places: uint8(places)}
+}
+
+// MinorUnits returns the value in minor units of the given number of places,
+// e.g. 1250 for 12.50 with two places. The value must fit in places.
+func (d Decimal) MinorUnits(places int) int64 {
+	return d.WithPlaces(places).units / pow10(MaxPlaces-places)
+}
+
+// MarshalJSON encodes the decimal as a JSON string to avoid float rounding in clients
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a JSON string or, for older clients, a JSON number.
+// Numbers are parsed from their literal text and never go through float64.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText encodes the decimal as its exact text, for encoders such as
+// MessagePack that use encoding.TextMarshaler
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText parses text written by MarshalText
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores the decimal as its exact text, which Postgres numeric keeps as is
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a numeric column. The column may hold more fraction digits than
+// a Decimal; those are rounded half away from zero to MaxPlaces.
+func (d *Decimal) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		*d = Zero
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("cannot scan %T into money.Decimal", value)
+	}
+
+	parsed, err := parse(s, true)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
@@ -0,0 +1,161 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"12", "12", nil},
+		{"12.50", "12.50", nil},
+		{"+3.5", "3.5", nil},
+		{"-0.5", "-0.5", nil},
+		{" 1999.99 ", "1999.99", nil},
+		{".25", "0.25", nil},
+		{"1.2345", "1.2345", nil},
+		{"1.230000", "1.2300", nil},
+		{"1.23456", "", ErrTooManyPlaces},
+		{"922337203685477", "922337203685477", nil},
+		{"922337203685478", "", ErrOutOfRange},
+		{"99999999999999999999", "", ErrOutOfRange},
+		{"", "", ErrInvalidDecimal},
+		{"-", "", ErrInvalidDecimal},
+		{"1.", "", ErrInvalidDecimal},
+		{"1e3", "", ErrInvalidDecimal},
+		{"1,000", "", ErrInvalidDecimal},
+		{"--1", "", ErrInvalidDecimal},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithPlaces(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int
+		want   string
+	}{
+		{"1.005", 2, "1.01"},
+		{"1.004", 2, "1.00"},
+		{"-1.005", 2, "-1.01"},
+		{"-1.004", 2, "-1.00"},
+		{"2.5", 0, "3"},
+		{"-2.5", 0, "-3"},
+		{"12.5", 2, "12.50"},
+		{"0.0049", 2, "0.00"},
+		{"7", 4, "7.0000"},
+		{"1.23", -1, "1"},
+		{"1.23", 9, "1.2300"},
+	}
+
+	for _, tt := range tests {
+		if got := MustParse(tt.in).WithPlaces(tt.places).String(); got != tt.want {
+			t.Errorf("%s.WithPlaces(%d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalMinorUnits(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int
+		want   int64
+	}{
+		{"12.50", 2, 1250},
+		{"-12.5", 2, -1250},
+		{"1000", 0, 1000},
+		{"1.2345", 3, 1235},
+		{"0.001", 4, 10},
+	}
+
+	for _, tt := range tests {
+		if got := MustParse(tt.in).MinorUnits(tt.places); got != tt.want {
+			t.Errorf("%s.MinorUnits(%d) = %d, want %d", tt.in, tt.places, got, tt.want)
+		}
+	}
+
+	d, err := NewFromMinor(1250, 2)
+	if err != nil || d.String() != "12.50" {
+		t.Errorf("NewFromMinor(1250, 2) = %s, %v, want 12.50", d, err)
+	}
+	if _, err := NewFromMinor(1, 5); !errors.Is(err, ErrTooManyPlaces) {
+		t.Errorf("NewFromMinor with 5 places error = %v, want ErrTooManyPlaces", err)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	data, err := json.Marshal(MustParse("12.50"))
+	if err != nil || string(data) != `"12.50"` {
+		t.Fatalf("Marshal = %s, %v, want \"12.50\"", data, err)
+	}
+
+	for in, want := range map[string]string{`"12.50"`: "12.50", `19.99`: "19.99", `"-3"`: "-3"} {
+		var d Decimal
+		if err := json.Unmarshal([]byte(in), &d); err != nil || d.String() != want {
+			t.Errorf("Unmarshal(%s) = %s, %v, want %s", in, d, err, want)
+		}
+	}
+
+	d := MustParse("5")
+	if err := json.Unmarshal([]byte("null"), &d); err != nil || d.String() != "5" {
+		t.Errorf("Unmarshal(null) changed the value to %s, %v", d, err)
+	}
+	for _, in := range []string{`"abc"`, `1.23456`, `true`} {
+		if err := json.Unmarshal([]byte(in), &d); err == nil {
+			t.Errorf("expected Unmarshal(%s) to fail", in)
+		}
+	}
+}
+
+func TestDecimalSQL(t *testing.T) {
+	value, err := MustParse("-19.90").Value()
+	if err != nil || value != "-19.90" {
+		t.Fatalf("Value = %v, %v, want -19.90", value, err)
+	}
+
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{value, "-19.90"},
+		{[]byte("12.50"), "12.50"},
+		{int64(7), "7"},
+		{float64(2.5), "2.5"},
+		{nil, "0"},
+		// Unconstrained numeric columns can hold more places than a Decimal
+		{"1.23455", "1.2346"},
+		{"-1.23455", "-1.2346"},
+		{"1.23454", "1.2345"},
+		{"0.99995", "1.0000"},
+	}
+
+	for _, tt := range tests {
+		var d Decimal
+		if err := d.Scan(tt.in); err != nil || d.String() != tt.want {
+			t.Errorf("Scan(%v) = %s, %v, want %s", tt.in, d, err, tt.want)
+		}
+	}
+
+	var d Decimal
+	if err := d.Scan(true); err == nil {
+		t.Error("expected scanning a bool to fail")
+	}
+	if err := d.Scan("not a number"); err == nil {
+		t.Error("expected scanning text to fail")
+	}
+}
@@ -1,10 +1,12 @@
 package ratelimit
 
 import (
+	"context"
 	"fmt"
 	"goapi-starter/internal/cache"
 	"goapi-starter/internal/logger"
 	"goapi-starter/internal/metrics"
+	"strconv"
 	"time"
 )
 
@@ -17,9 +19,9 @@ const (
 	DefaultBlockDuration = 300 // seconds (5 minute block after exceeding limit)
 
 	// Redis key prefixes
-	IPLimitPrefix   = "ratelimit:ip:"
-	UserLimitPrefix = "ratelimit:user:"
-	AuthLimitPrefix = "ratelimit:auth:"
+	IPLimitPrefix   = cache.RateLimitPrefix + "ip:"
+	UserLimitPrefix = cache.RateLimitPrefix + "user:"
+	AuthLimitPrefix = cache.RateLimitPrefix + "auth:"
 )
 
 // RateLimiter defines the configuration for rate limiting
@@ -32,6 +34,10 @@ type RateLimiter struct {
 	BlockDuration int
 	// Key prefix for Redis
 	KeyPrefix string
+	// Store holding the counters; cache.Backend when nil
+	Store cache.Store
+	// What to do when the store fails; CACHE_RATELIMIT_FAILURE_POLICY when empty
+	FailurePolicy cache.FailurePolicy
 }
 
 // NewIPRateLimiter creates a rate limiter for IP-based limiting
@@ -66,72 +72,75 @@ func NewAuthRateLimiter() *RateLimiter {
 
 // Allow checks if a request should be allowed based on the rate limit
 // Returns: allowed (bool), remaining (int), resetAfter (time.Duration), err (error)
+// When the store fails, the request is allowed or rejected by the failure
+// policy and the error is returned either way.
 func (rl *RateLimiter) Allow(identifier string) (bool, int, time.Duration, error) {
 	metrics.RecordRateLimitCheck(rl.KeyPrefix)
+	ctx := context.Background()
+	store := rl.store()
 
 	// Check if the identifier is currently blocked
-	blockedKey := fmt.Sprintf("%s%s:blocked", rl.KeyPrefix, identifier)
-	var blockedUntil int64
-	blocked, err := cache.Get(blockedKey, &blockedUntil)
+	blockedKey := rl.key(identifier) + ":blocked"
+	value, blocked, err := store.Get(ctx, blockedKey)
 
 	if err != nil {
 		logger.Warn().
 			Err(err).
 			Str("identifier", identifier).
 			Msg("Error checking rate limit block status")
-		// On error, we'll continue and assume not blocked
+		if rl.failClosed() {
+			metrics.RecordRateLimitResult(rl.KeyPrefix, "error")
+			return false, 0, time.Duration(rl.WindowSize) * time.Second, err
+		}
+		// Otherwise continue and assume not blocked
 	} else if blocked {
 		now := time.Now().Unix()
-		if blockedUntil > now {
+		if blockedUntil, err := strconv.ParseInt(string(value), 10, 64); err == nil && blockedUntil > now {
 			// Still blocked
 			resetAfter := time.Duration(blockedUntil-now) * time.Second
 			metrics.RecordRateLimitResult(rl.KeyPrefix, "blocked")
 			return false, 0, resetAfter, nil
 		}
-		// Block has expired, remove it
-		_ = cache.Delete(blockedKey)
 	}
 
-	// Get the current count
-	key := fmt.Sprintf("%s%s", rl.KeyPrefix, identifier)
-	var count int
-	exists, err := cache.Get(key, &count)
+	// Count the request and read the window's remaining time in one round trip
+	key := rl.key(identifier)
+	windowExpiry := time.Duration(rl.WindowSize) * time.Second
 
-	if err != nil {
+	pipe := store.Pipeline()
+	incr := pipe.Incr(key)
+	ttl := pipe.TTL(key)
+	if err := pipe.Exec(ctx); err != nil {
 		logger.Warn().
 			Err(err).
 			Str("identifier", identifier).
-			Msg("Error checking rate limit count")
-		// On error, we'll allow the request but not increment
+			Msg("Error updating rate limit count")
 		metrics.RecordRateLimitResult(rl.KeyPrefix, "error")
-		return true, rl.Limit, time.Duration(rl.WindowSize) * time.Second, err
+		if rl.failClosed() {
+			return false, 0, windowExpiry, err
+		}
+		return true, rl.Limit, windowExpiry, err
 	}
 
-	now := time.Now()
-	windowExpiry := time.Duration(rl.WindowSize) * time.Second
-
-	if !exists {
-		// First request in this window
-		count = 1
-		err = cache.SetWithTTL(key, count, windowExpiry)
-		if err != nil {
+	// The first request opens the window
+	resetAfter := ttl.Val()
+	if resetAfter <= 0 {
+		if _, err := store.Expire(ctx, key, windowExpiry); err != nil {
 			logger.Warn().
 				Err(err).
 				Str("identifier", identifier).
-				Msg("Error setting initial rate limit count")
+				Msg("Error setting rate limit window")
 		}
-		metrics.RecordRateLimitResult(rl.KeyPrefix, "allowed")
-		return true, rl.Limit - 1, windowExpiry, nil
+		resetAfter = windowExpiry
 	}
 
-	// Increment the counter
-	count++
-
 	// Check if limit exceeded
+	count := int(incr.Val())
 	if count > rl.Limit {
 		// Block the identifier for the block duration
-		blockUntil := now.Add(time.Duration(rl.BlockDuration) * time.Second).Unix()
-		err = cache.SetWithTTL(blockedKey, blockUntil, time.Duration(rl.BlockDuration)*time.Second)
+		blockDuration := time.Duration(rl.BlockDuration) * time.Second
+		blockUntil := time.Now().Add(blockDuration).Unix()
+		err = store.Set(ctx, blockedKey, strconv.AppendInt(nil, blockUntil, 10), blockDuration)
 		if err != nil {
 			logger.Warn().
 				Err(err).
@@ -140,25 +149,37 @@ func (rl *RateLimiter) Allow(identifier string) (bool, int, time.Duration, error
 		}
 
 		metrics.RecordRateLimitResult(rl.KeyPrefix, "exceeded")
-		return false, 0, time.Duration(rl.BlockDuration) * time.Second, nil
+		return false, 0, blockDuration, nil
 	}
 
-	// Update the counter
-	// Get TTL of the existing key to maintain the same window
-	ttl, err := cache.GetTTL(key)
-	if err != nil || ttl <= 0 {
-		ttl = windowExpiry
-	}
+	metrics.RecordRateLimitResult(rl.KeyPrefix, "allowed")
+	return true, rl.Limit - count, resetAfter, nil
+}
 
-	err = cache.SetWithTTL(key, count, ttl)
-	if err != nil {
-		logger.Warn().
-			Err(err).
-			Str("identifier", identifier).
-			Int("count", count).
-			Msg("Error updating rate limit count")
+// key returns the counter key of an identifier. The identifier is a hash tag,
+// so in Redis Cluster its counter and block keys share a slot.
+func (rl *RateLimiter) key(identifier string) string {
+	return fmt.Sprintf("%s{%s}", rl.KeyPrefix, identifier)
+}
+
+// store returns the limiter's store, defaulting to the application cache
+func (rl *RateLimiter) store() cache.Store {
+	if rl.Store != nil {
+		return rl.Store
 	}
+	return cache.Backend
+}
 
-	metrics.RecordRateLimitResult(rl.KeyPrefix, "allowed")
-	return true, rl.Limit - count, ttl, nil
+// failClosed reports whether requests are rejected when the store fails
+func (rl *RateLimiter) failClosed() bool {
+	policy := rl.FailurePolicy
+	if policy == "" {
+		policy = cache.RateLimitPolicy()
+	}
+	if policy == cache.FailClosed {
+		metrics.RecordCacheDegradedDecision("rate_limit", "rejected")
+		return true
+	}
+	metrics.RecordCacheDegradedDecision("rate_limit", "allowed")
+	return false
 }
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"goapi-starter/internal/cache"
+	"testing"
+	"time"
+)
+
+func TestAllowBlocksAfterLimit(t *testing.T) {
+	store := cache.NewMemoryStore()
+	defer store.Close()
+
+	rl := &RateLimiter{Limit: 3, WindowSize: 60, BlockDuration: 300, KeyPrefix: "ratelimit:test:", Store: store}
+
+	for i := 1; i <= 3; i++ {
+		allowed, remaining, resetAfter, err := rl.Allow("client")
+		if err != nil || !allowed {
+			t.Fatalf("request %d: allowed=%v err=%v", i, allowed, err)
+		}
+		if remaining != 3-i {
+			t.Errorf("request %d: remaining = %d, want %d", i, remaining, 3-i)
+		}
+		if resetAfter <= 0 || resetAfter > time.Minute {
+			t.Errorf("request %d: resetAfter = %v", i, resetAfter)
+		}
+	}
+
+	allowed, _, resetAfter, err := rl.Allow("client")
+	if err != nil || allowed {
+		t.Fatalf("expected the fourth request to be rejected, allowed=%v err=%v", allowed, err)
+	}
+	if resetAfter != 5*time.Minute {
+		t.Errorf("resetAfter = %v, want the block duration", resetAfter)
+	}
+
+	if allowed, _, _, _ := rl.Allow("client"); allowed {
+		t.Error("expected a blocked client to stay blocked")
+	}
+	if allowed, _, _, _ := rl.Allow("other"); !allowed {
+		t.Error("expected other clients to be unaffected")
+	}
+}
+
+// downStore fails like an unreachable cache
+type downStore struct{ cache.Store }
+
+func (downStore) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, cache.ErrCacheUnavailable
+}
+
+func (s downStore) Pipeline() cache.Pipeline { return downPipeline{s.Store.Pipeline()} }
+
+type downPipeline struct{ cache.Pipeline }
+
+func (downPipeline) Exec(context.Context) error { return cache.ErrCacheUnavailable }
+
+func TestAllowFailurePolicy(t *testing.T) {
+	store := cache.NewMemoryStore()
+	defer store.Close()
+
+	rl := &RateLimiter{Limit: 3, WindowSize: 60, BlockDuration: 300, KeyPrefix: "ratelimit:test:", Store: downStore{store}}
+
+	rl.FailurePolicy = cache.FailOpen
+	if allowed, _, _, err := rl.Allow("client"); !allowed || err == nil {
+		t.Errorf("open policy: allowed=%v err=%v, want allowed with the error", allowed, err)
+	}
+
+	rl.FailurePolicy = cache.FailClosed
+	if allowed, _, _, err := rl.Allow("client"); allowed || err == nil {
+		t.Errorf("closed policy: allowed=%v err=%v, want rejected with the error", allowed, err)
+	}
+}
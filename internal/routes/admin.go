@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"goapi-starter/internal/handlers"
+	customMiddleware "goapi-starter/internal/middleware"
+	"goapi-starter/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func AdminRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(customMiddleware.AdminMiddleware)
+
+	r.Route("/cache", func(r chi.Router) {
+		r.Get("/prefixes", utils.InstrumentHandler("GetCachePrefixes", handlers.GetCachePrefixes))
+		r.Delete("/prefixes/{prefix}", utils.InstrumentHandler("PurgeCachePrefix", handlers.PurgeCachePrefix))
+		r.Get("/key", utils.InstrumentHandler("GetCacheKey", handlers.GetCacheKey))
+	})
+
+	return r
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"goapi-starter/internal/handlers"
+	customMiddleware "goapi-starter/internal/middleware"
+	"goapi-starter/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func CategoryRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", utils.InstrumentHandler("GetCategories", handlers.GetCategories))
+	r.With(customMiddleware.IdempotencyMiddleware).Post("/", utils.InstrumentHandler("CreateCategory", handlers.CreateCategory))
+	r.Get("/{id}", utils.InstrumentHandler("GetCategory", handlers.GetCategory))
+	r.Put("/{id}", utils.InstrumentHandler("UpdateCategory", handlers.UpdateCategory))
+	r.Delete("/{id}", utils.InstrumentHandler("DeleteCategory", handlers.DeleteCategory))
+
+	return r
+}
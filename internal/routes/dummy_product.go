@@ -2,6 +2,7 @@ package routes
 
 import (
 	"goapi-starter/internal/handlers"
+	customMiddleware "goapi-starter/internal/middleware"
 	"goapi-starter/internal/utils"
 
 	"github.com/go-chi/chi/v5"
@@ -10,11 +11,29 @@ import (
 func DummyProductRoutes() chi.Router {
 	r := chi.NewRouter()
 
-	r.Post("/", utils.InstrumentHandler("CreateDummyProduct", handlers.CreateDummyProduct))
+	// Idempotency-Key support buffers the request body, so it only covers JSON
+	// mutations; the streaming import and image uploads are left out
+	idempotent := r.With(customMiddleware.IdempotencyMiddleware)
+
+	idempotent.Post("/", utils.InstrumentHandler("CreateDummyProduct", handlers.CreateDummyProduct))
 	r.Get("/", utils.InstrumentHandler("GetDummyProducts", handlers.GetDummyProducts))
+	idempotent.Post("/batch", utils.InstrumentHandler("BatchDummyProducts", handlers.BatchDummyProducts))
+	r.Get("/export", utils.InstrumentHandler("ExportDummyProducts", handlers.ExportDummyProducts))
+	r.Get("/exports/{id}", utils.InstrumentHandler("DownloadDummyProductExport", handlers.DownloadDummyProductExport))
+	r.Post("/import", utils.InstrumentHandler("ImportDummyProducts", handlers.ImportDummyProducts))
+	r.Get("/search", utils.InstrumentHandler("SearchDummyProducts", handlers.SearchDummyProducts))
+	r.Get("/trash", utils.InstrumentHandler("GetDeletedDummyProducts", handlers.GetDeletedDummyProducts))
 	r.Get("/{id}", utils.InstrumentHandler("GetDummyProduct", handlers.GetDummyProduct))
 	r.Put("/{id}", utils.InstrumentHandler("UpdateDummyProduct", handlers.UpdateDummyProduct))
+	idempotent.Patch("/{id}", utils.InstrumentHandler("PatchDummyProduct", handlers.PatchDummyProduct))
 	r.Delete("/{id}", utils.InstrumentHandler("DeleteDummyProduct", handlers.DeleteDummyProduct))
+	r.Post("/{id}/images", utils.InstrumentHandler("UploadDummyProductImage", handlers.UploadDummyProductImage))
+	r.Get("/{id}/images", utils.InstrumentHandler("GetDummyProductImages", handlers.GetDummyProductImages))
+	r.Delete("/{id}/images/{imageID}", utils.InstrumentHandler("DeleteDummyProductImage", handlers.DeleteDummyProductImage))
+	r.Get("/{id}/history", utils.InstrumentHandler("GetDummyProductHistory", handlers.GetDummyProductHistory))
+	idempotent.Post("/{id}/restore", utils.InstrumentHandler("RestoreDummyProduct", handlers.RestoreDummyProduct))
+	idempotent.Post("/{id}/reserve", utils.InstrumentHandler("ReserveDummyProduct", handlers.ReserveDummyProduct))
+	r.Put("/{id}/stock", utils.InstrumentHandler("UpdateDummyProductStock", handlers.UpdateDummyProductStock))
 
 	return r
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"goapi-starter/internal/handlers"
+	"goapi-starter/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func JobRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/{id}", utils.InstrumentHandler("GetJob", handlers.GetJob))
+
+	return r
+}
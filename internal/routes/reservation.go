@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"goapi-starter/internal/handlers"
+	customMiddleware "goapi-starter/internal/middleware"
+	"goapi-starter/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func ReservationRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/{id}", utils.InstrumentHandler("GetReservation", handlers.GetReservation))
+	idempotent := r.With(customMiddleware.IdempotencyMiddleware)
+	idempotent.Post("/{id}/confirm", utils.InstrumentHandler("ConfirmReservation", handlers.ConfirmReservation))
+	idempotent.Post("/{id}/cancel", utils.InstrumentHandler("CancelReservation", handlers.CancelReservation))
+
+	return r
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"goapi-starter/internal/handlers"
 	customMiddleware "goapi-starter/internal/middleware"
+	"goapi-starter/internal/storage"
 	"goapi-starter/internal/utils"
 
 	"github.com/go-chi/chi/v5"
@@ -21,9 +22,9 @@ func SetupRouter() *chi.Mux {
 	// CORS middleware
 	corsMiddleware := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"}, // In production, specify exact domains
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match", "If-None-Match", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "ETag", "Accept-Patch", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	})
@@ -43,6 +44,9 @@ func SetupRouter() *chi.Mux {
 		r.Handle("/metrics", promhttp.Handler())
 	})
 
+	// Locally stored files, authorized by the signature in their URL
+	r.Get(storage.LocalFilesPath+"*", utils.InstrumentHandler("ServeFile", handlers.ServeFile))
+
 	// Auth routes with stricter rate limiting
 	r.Group(func(r chi.Router) {
 		r.Use(customMiddleware.AuthRateLimitMiddleware)
@@ -54,10 +58,19 @@ func SetupRouter() *chi.Mux {
 		r.Use(customMiddleware.AuthMiddleware)
 		r.Use(customMiddleware.UserRateLimitMiddleware)
 		r.Mount("/api/dummy-products", DummyProductRoutes())
+		r.Mount("/api/categories", CategoryRoutes())
+		r.Mount("/api/tags", TagRoutes())
+		r.Mount("/api/reservations", ReservationRoutes())
 
 		// User routes
 		r.Mount("/api/user", UserRoutes())
 
+		// Background job status
+		r.Mount("/api/jobs", JobRoutes())
+
+		// Administration, restricted to admins
+		r.Mount("/api/admin", AdminRoutes())
+
 		// Logout route
 		r.Post("/api/auth/logout", utils.InstrumentHandler("Logout", handlers.Logout))
 	})
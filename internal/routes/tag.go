@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"goapi-starter/internal/handlers"
+	customMiddleware "goapi-starter/internal/middleware"
+	"goapi-starter/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TagRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", utils.InstrumentHandler("GetTags", handlers.GetTags))
+	r.With(customMiddleware.IdempotencyMiddleware).Post("/", utils.InstrumentHandler("CreateTag", handlers.CreateTag))
+	r.Put("/{id}", utils.InstrumentHandler("UpdateTag", handlers.UpdateTag))
+	r.Delete("/{id}", utils.InstrumentHandler("DeleteTag", handlers.DeleteTag))
+
+	return r
+}
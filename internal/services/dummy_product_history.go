@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/models"
+	"time"
+)
+
+// GetDummyProductHistory returns up to limit history entries of a product,
+// newest first, starting below the entry ID before when it is set
+func GetDummyProductHistory(ctx context.Context, productID uint, before uint64, limit int) ([]models.DummyProductHistory, error) {
+	db := database.DB.WithContext(ctx).Where("dummy_product_id = ?", productID)
+	if before > 0 {
+		db = db.Where("id < ?", before)
+	}
+
+	entries := []models.DummyProductHistory{}
+	if err := db.Order("id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// DummyProductAsOf reconstructs a product as it was at the given moment by
+// replaying its recorded changes. Products that did not exist yet, were in the
+// trash or had been purged at that moment are reported as not found. Only the
+// product's own columns are reconstructed, not its category or tags.
+func DummyProductAsOf(ctx context.Context, productID uint, at time.Time) (*models.DummyProduct, error) {
+	var entries []models.DummyProductHistory
+	err := database.DB.WithContext(ctx).
+		Where("dummy_product_id = ? AND changed_at <= ?", productID, at).
+		Order("id ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]json.RawMessage)
+	for _, entry := range entries {
+		if entry.Action == models.HistoryActionPurge {
+			state = make(map[string]json.RawMessage)
+			continue
+		}
+		for column, change := range entry.Changes {
+			state[column] = change.New
+		}
+	}
+	if len(state) == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	var product models.DummyProduct
+	if err := json.Unmarshal(data, &product); err != nil {
+		return nil, err
+	}
+	if product.DeletedAt.Valid {
+		return nil, ErrProductNotFound
+	}
+
+	return &product, nil
+}
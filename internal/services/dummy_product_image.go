@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/imaging"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/storage"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrImageNotFound is returned when the image does not exist for the product
+	ErrImageNotFound = errors.New("dummy product image not found")
+	// ErrUnsupportedImage is returned when the content is not a supported image
+	ErrUnsupportedImage = errors.New("unsupported image type")
+	// ErrImageTooLarge is returned when the image has more pixels than allowed
+	ErrImageTooLarge = errors.New("image dimensions exceed the limit")
+)
+
+// imageExtensions maps the accepted image types to their stored extension
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// SniffImageType detects the content type from the leading bytes, ignoring
+// whatever the client claimed, and reports whether it is accepted
+func SniffImageType(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	_, ok := imageExtensions[contentType]
+	return contentType, ok
+}
+
+// UploadDummyProductImage stores an image of a product together with its
+// thumbnails. The dimensions are checked before decoding so oversized images
+// never get expanded in memory.
+func UploadDummyProductImage(ctx context.Context, productID uint, filename string, data []byte) (*models.DummyProductImage, error) {
+	var count int64
+	if err := database.DB.WithContext(ctx).Model(&models.DummyProduct{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	contentType, ok := SniffImageType(data)
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > config.AppConfig.Products.ImageMaxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	img := &models.DummyProductImage{
+		ID:             uuid.NewString(),
+		DummyProductID: productID,
+		Filename:       sanitizeFilename(filename),
+		ContentType:    contentType,
+		Extension:      imageExtensions[contentType],
+		Size:           int64(len(data)),
+		Width:          cfg.Width,
+		Height:         cfg.Height,
+	}
+
+	var stored []string
+	cleanup := func() {
+		storage.DeleteAll(context.WithoutCancel(ctx), storage.Blobs, stored)
+	}
+
+	if err := storage.Blobs.Put(ctx, img.OriginalKey(), bytes.NewReader(data), img.Size, contentType); err != nil {
+		return nil, err
+	}
+	stored = append(stored, img.OriginalKey())
+
+	flat := imaging.Flatten(decoded)
+	var sizes []string
+	for _, size := range config.AppConfig.Products.ThumbnailSizes {
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, imaging.Thumbnail(flat, size)); err != nil {
+			cleanup()
+			return nil, err
+		}
+		if err := storage.Blobs.Put(ctx, img.ThumbnailKey(size), &buf, int64(buf.Len()), "image/jpeg"); err != nil {
+			cleanup()
+			return nil, err
+		}
+		stored = append(stored, img.ThumbnailKey(size))
+		sizes = append(sizes, strconv.Itoa(size))
+	}
+	img.ThumbnailSizes = strings.Join(sizes, ",")
+
+	if err := database.DB.WithContext(ctx).Create(img).Error; err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	logger.Info().
+		Str("image_id", img.ID).
+		Uint("product_id", productID).
+		Str("content_type", contentType).
+		Int64("size", img.Size).
+		Int("width", img.Width).
+		Int("height", img.Height).
+		Msg("Dummy product image stored")
+
+	return img, nil
+}
+
+// ListDummyProductImages returns the images of a product, oldest first
+func ListDummyProductImages(ctx context.Context, productID uint) ([]models.DummyProductImage, error) {
+	var count int64
+	if err := database.DB.WithContext(ctx).Model(&models.DummyProduct{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrProductNotFound
+	}
+
+	images := []models.DummyProductImage{}
+	err := database.DB.WithContext(ctx).
+		Where("dummy_product_id = ?", productID).
+		Order("created_at, id").
+		Find(&images).Error
+	return images, err
+}
+
+// DeleteDummyProductImage removes an image and its blobs. Blobs that fail to
+// delete are logged and left behind rather than failing the request.
+func DeleteDummyProductImage(ctx context.Context, productID uint, imageID string) error {
+	var img models.DummyProductImage
+	err := database.DB.WithContext(ctx).
+		Where("id = ? AND dummy_product_id = ?", imageID, productID).
+		First(&img).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrImageNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.WithContext(ctx).Delete(&img).Error; err != nil {
+		return err
+	}
+
+	storage.DeleteAll(context.WithoutCancel(ctx), storage.Blobs, img.Keys())
+	return nil
+}
+
+// SignDummyProductImage fills in signed download URLs for the original and
+// each thumbnail, keyed by "original" and the thumbnail size
+func SignDummyProductImage(ctx context.Context, img *models.DummyProductImage) error {
+	ttl := config.AppConfig.Products.ImageURLTTL
+
+	urls := map[string]string{}
+	original, err := storage.Blobs.SignedURL(ctx, img.OriginalKey(), ttl)
+	if err != nil {
+		return err
+	}
+	urls["original"] = original
+
+	for _, size := range img.Thumbnails() {
+		url, err := storage.Blobs.SignedURL(ctx, img.ThumbnailKey(size), ttl)
+		if err != nil {
+			return err
+		}
+		urls[strconv.Itoa(size)] = url
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	img.URLs = urls
+	img.ExpiresAt = &expiresAt
+	return nil
+}
+
+// sanitizeFilename keeps only the base name of a client supplied file name
+func sanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/money"
+	"goapi-starter/internal/utils"
+	"io"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ImportFormatCSV is a CSV file with a header row
+	ImportFormatCSV = "csv"
+	// ImportFormatNDJSON is newline-delimited JSON, one product per line
+	ImportFormatNDJSON = "ndjson"
+
+	// ImportModeAtomic imports every row or none of them
+	ImportModeAtomic = "atomic"
+	// ImportModeBestEffort imports every valid row and reports the rest
+	ImportModeBestEffort = "best_effort"
+
+	// importBatchSize is the number of rows inserted per statement
+	importBatchSize = 500
+	// maxImportErrors bounds the size of the error report
+	maxImportErrors = 1000
+	// maxNDJSONLineSize bounds a single NDJSON line
+	maxNDJSONLineSize = 1 << 20
+)
+
+// ImportRowError is a row-level parse error. Reading can continue after it.
+type ImportRowError struct {
+	Line int
+	Err  error
+}
+
+func (e *ImportRowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// DummyProductRowReader streams dummy product rows from an import source
+type DummyProductRowReader interface {
+	// Next returns the next row and its line number. It returns io.EOF at the
+	// end of the stream and *ImportRowError for rows that cannot be parsed;
+	// any other error is fatal.
+	Next() (int, models.DummyProductRequest, error)
+}
+
+// NewDummyProductRowReader returns a streaming row reader for the given format
+func NewDummyProductRowReader(format string, r io.Reader) (DummyProductRowReader, error) {
+	switch format {
+	case ImportFormatCSV:
+		return newCSVRowReader(r)
+	case ImportFormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxNDJSONLineSize)
+		return &ndjsonRowReader{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+type csvRowReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVRowReader(r io.Reader) (*csvRowReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", required)
+		}
+	}
+
+	return &csvRowReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvRowReader) Next() (int, models.DummyProductRequest, error) {
+	record, err := c.reader.Read()
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.StartLine, models.DummyProductRequest{}, &ImportRowError{Line: parseErr.StartLine, Err: parseErr.Err}
+	}
+	if err != nil {
+		return 0, models.DummyProductRequest{}, err
+	}
+	line, _ := c.reader.FieldPos(0)
+
+	field := func(name string) string {
+		if i, ok := c.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	price, err := money.Parse(field("price"))
+	if err != nil {
+		return line, models.DummyProductRequest{}, &ImportRowError{Line: line, Err: errors.New("price must be a decimal number")}
+	}
+
+	return line, models.DummyProductRequest{
+		Name:        field("name"),
+		Description: field("description"),
+		Price:       price,
+		Currency:    field("currency"),
+	}, nil
+}
+
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonRowReader) Next() (int, models.DummyProductRequest, error) {
+	for n.scanner.Scan() {
+		n.line++
+		text := strings.TrimSpace(n.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var req models.DummyProductRequest
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			return n.line, req, &ImportRowError{Line: n.line, Err: errors.New("invalid JSON")}
+		}
+		return n.line, req, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return n.line, models.DummyProductRequest{}, err
+	}
+	return n.line, models.DummyProductRequest{}, io.EOF
+}
+
+// pendingRow is a validated row waiting to be inserted
+type pendingRow struct {
+	line    int
+	product models.DummyProduct
+}
+
+// dummyProductImporter accumulates rows into batches and tracks the report
+type dummyProductImporter struct {
+	ctx             context.Context
+	ownerID         *string
+	mode            string
+	defaultCurrency string
+	tx              *gorm.DB // only set in atomic mode
+	batch           []pendingRow
+	report          models.DummyProductImportReport
+	onProgress      func(models.DummyProductImportReport)
+}
+
+// ImportDummyProducts reads every row, validates it against DummyProductRequest
+// and inserts valid rows in batches. In atomic mode everything runs in one
+// transaction that is rolled back if any row fails; in best-effort mode each
+// batch commits on its own and failing rows are reported individually.
+// Rows without a currency are priced in defaultCurrency. onProgress, if set,
+// is called after every batch.
+func ImportDummyProducts(ctx context.Context, rows DummyProductRowReader, format, mode, defaultCurrency string, onProgress func(models.DummyProductImportReport)) (models.DummyProductImportReport, error) {
+	imp := &dummyProductImporter{
+		ctx:             ctx,
+		mode:            mode,
+		defaultCurrency: defaultCurrency,
+		report:          models.DummyProductImportReport{Format: format, Mode: mode, Errors: []models.DummyProductImportError{}},
+		onProgress:      onProgress,
+	}
+	if userID, ok := utils.GetUserIDFromContext(ctx); ok {
+		imp.ownerID = &userID
+	}
+
+	if mode == ImportModeAtomic {
+		imp.tx = database.DB.WithContext(ctx).Begin()
+		if imp.tx.Error != nil {
+			return imp.report, imp.tx.Error
+		}
+	}
+
+	err := imp.run(rows)
+
+	if imp.tx != nil {
+		if err != nil || imp.report.Failed > 0 {
+			imp.tx.Rollback()
+			imp.report.Imported = 0
+		} else if commitErr := imp.tx.Commit().Error; commitErr != nil {
+			imp.report.Imported = 0
+			err = commitErr
+		} else {
+			imp.report.Committed = true
+		}
+	} else {
+		imp.report.Committed = imp.report.Imported > 0
+	}
+
+	logger.Info().
+		Str("format", format).
+		Str("mode", mode).
+		Int("processed", imp.report.Processed).
+		Int("imported", imp.report.Imported).
+		Int("failed", imp.report.Failed).
+		Bool("committed", imp.report.Committed).
+		Msg("Dummy product import finished")
+
+	return imp.report, err
+}
+
+func (imp *dummyProductImporter) run(rows DummyProductRowReader) error {
+	for {
+		if err := imp.ctx.Err(); err != nil {
+			return err
+		}
+
+		line, req, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+
+		var rowErr *ImportRowError
+		if errors.As(err, &rowErr) {
+			imp.report.Processed++
+			imp.recordError(rowErr.Line, rowErr.Err.Error())
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		imp.report.Processed++
+		req = req.WithDefaultCurrency(imp.defaultCurrency)
+		if err := utils.ValidateStruct(req); err != nil {
+			imp.recordError(line, err.Error())
+			continue
+		}
+
+		price := req.Money()
+		imp.batch = append(imp.batch, pendingRow{
+			line: line,
+			product: models.DummyProduct{
+				Name:        req.Name,
+				Description: req.Description,
+				Price:       price.Amount,
+				Currency:    price.Currency,
+				OwnerID:     imp.ownerID,
+			},
+		})
+
+		if len(imp.batch) >= importBatchSize {
+			if err := imp.flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return imp.flush()
+}
+
+// flush inserts the pending batch
+func (imp *dummyProductImporter) flush() error {
+	if len(imp.batch) == 0 {
+		return nil
+	}
+	defer func() {
+		imp.batch = imp.batch[:0]
+		if imp.onProgress != nil {
+			imp.onProgress(imp.report)
+		}
+	}()
+
+	products := make([]models.DummyProduct, len(imp.batch))
+	for i, row := range imp.batch {
+		products[i] = row.product
+	}
+
+	if imp.tx != nil {
+		// Once an atomic import has failed nothing will be committed, so
+		// keep validating the remaining rows but stop writing
+		if imp.report.Failed > 0 {
+			return nil
+		}
+		if err := imp.tx.Create(&products).Error; err != nil {
+			return err
+		}
+		imp.report.Imported += len(products)
+		return nil
+	}
+
+	err := database.DB.WithContext(imp.ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&products).Error
+	})
+	if err == nil {
+		imp.report.Imported += len(products)
+		return nil
+	}
+
+	// The batch failed as a whole; retry rows one by one to find the culprits
+	logger.Warn().Err(err).Int("batch_size", len(products)).Msg("Import batch failed, retrying rows individually")
+	for _, row := range imp.batch {
+		product := row.product
+		if err := database.DB.WithContext(imp.ctx).Create(&product).Error; err != nil {
+			imp.recordError(row.line, "unable to store row")
+			continue
+		}
+		imp.report.Imported++
+	}
+	return nil
+}
+
+func (imp *dummyProductImporter) recordError(line int, message string) {
+	imp.report.Failed++
+	if len(imp.report.Errors) >= maxImportErrors {
+		imp.report.ErrorsTruncated = true
+		return
+	}
+	imp.report.Errors = append(imp.report.Errors, models.DummyProductImportError{Line: line, Error: message})
+}
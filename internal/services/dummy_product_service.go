@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/storage"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// purgeBatchSize bounds how many rows a single purge statement deletes
+const purgeBatchSize = 1000
+
+// PurgeDeletedDummyProducts permanently removes products that have been in the
+// trash for longer than the retention window, along with their stored images.
+// Rows are deleted in batches so a large backlog never holds long locks.
+func PurgeDeletedDummyProducts(ctx context.Context, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+	var purged int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+
+		var ids []uint
+		err := database.DB.WithContext(ctx).Unscoped().
+			Model(&models.DummyProduct{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Limit(purgeBatchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			logger.Error().Err(err).Int64("purged", purged).Msg("Failed to select deleted dummy products")
+			return purged, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		// Image rows cascade with their product, so their blob keys are read first
+		var images []models.DummyProductImage
+		if err := database.DB.WithContext(ctx).Where("dummy_product_id IN ?", ids).Find(&images).Error; err != nil {
+			logger.Error().Err(err).Int64("purged", purged).Msg("Failed to load images of deleted dummy products")
+			return purged, err
+		}
+
+		// A product restored since it was selected is skipped and keeps its images
+		var deleted []models.DummyProduct
+		result := database.DB.WithContext(ctx).Unscoped().
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+			Where("id IN ? AND deleted_at IS NOT NULL", ids).
+			Delete(&deleted)
+		if result.Error != nil {
+			logger.Error().Err(result.Error).Int64("purged", purged).Msg("Failed to purge deleted dummy products")
+			return purged, result.Error
+		}
+
+		purgedIDs := make(map[uint]bool, len(deleted))
+		for _, product := range deleted {
+			purgedIDs[product.ID] = true
+		}
+		var keys []string
+		for _, image := range images {
+			if purgedIDs[image.DummyProductID] {
+				keys = append(keys, image.Keys()...)
+			}
+		}
+		storage.DeleteAll(ctx, storage.Blobs, keys)
+
+		purged += result.RowsAffected
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+
+	if purged > 0 {
+		logger.Info().
+			Int64("purged", purged).
+			Time("cutoff", cutoff).
+			Msg("Purged deleted dummy products")
+	}
+
+	return purged, nil
+}
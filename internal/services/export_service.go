@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"goapi-starter/internal/logger"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PurgeExpiredExports removes generated export files older than maxAge. Once
+// the job that produced a file has expired nobody can download it anymore.
+func PurgeExpiredExports(ctx context.Context, dir string, maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			logger.Warn().Err(err).Str("file", entry.Name()).Msg("Failed to remove expired export")
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		logger.Info().
+			Int("removed", removed).
+			Str("dir", dir).
+			Msg("Purged expired exports")
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/logger"
+	"goapi-starter/internal/metrics"
+	"goapi-starter/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// releaseBatchSize bounds how many expired reservations are released per statement
+const releaseBatchSize = 1000
+
+var (
+	// ErrProductNotFound is returned when the product does not exist
+	ErrProductNotFound = errors.New("dummy product not found")
+	// ErrInsufficientStock is returned when fewer units are available than requested
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrStockBelowReserved is returned when stock would drop below the reserved units
+	ErrStockBelowReserved = errors.New("stock cannot be lower than the reserved quantity")
+	// ErrReservationNotFound is returned for unknown reservations or those of other users
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrReservationNotPending is returned when a reservation was already confirmed, cancelled or expired
+	ErrReservationNotPending = errors.New("reservation is no longer pending")
+	// ErrReservationExpired is returned when a reservation expired before it was confirmed
+	ErrReservationExpired = errors.New("reservation has expired")
+)
+
+// stockLevel is the stock of a product right after a change
+type stockLevel struct {
+	Available         int
+	LowStockThreshold int
+}
+
+// recordLowStock emits a low-stock event when a change of delta units moved
+// the available stock to or below the product's threshold
+func recordLowStock(productID uint, level stockLevel, delta int) {
+	if level.Available > level.LowStockThreshold || level.Available+delta <= level.LowStockThreshold {
+		return
+	}
+	metrics.InventoryLowStockEvents.Inc()
+	logger.Warn().
+		Uint("product_id", productID).
+		Int("available", level.Available).
+		Int("threshold", level.LowStockThreshold).
+		Msg("Dummy product stock is low")
+}
+
+// ReserveStock holds quantity units of a product for userID until ttl elapses.
+// The availability check and the increment happen in a single conditional
+// UPDATE, so concurrent reservations can never oversell.
+func ReserveStock(ctx context.Context, productID uint, userID string, quantity int, ttl time.Duration) (*models.StockReservation, error) {
+	reservation := &models.StockReservation{
+		DummyProductID: productID,
+		UserID:         userID,
+		Quantity:       quantity,
+		Status:         models.ReservationPending,
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+
+	var level stockLevel
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Raw(`UPDATE dummy_products
+			SET reserved_quantity = reserved_quantity + @quantity, version = version + 1, updated_at = now()
+			WHERE id = @id AND deleted_at IS NULL AND stock_quantity - reserved_quantity >= @quantity
+			RETURNING stock_quantity - reserved_quantity AS available, low_stock_threshold`,
+			map[string]interface{}{"id": productID, "quantity": quantity}).Scan(&level)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			tx.Model(&models.DummyProduct{}).Where("id = ?", productID).Count(&count)
+			if count == 0 {
+				return ErrProductNotFound
+			}
+			return ErrInsufficientStock
+		}
+		return tx.Create(reservation).Error
+	})
+	if err != nil {
+		metrics.RecordReservation("reserve", reservationResult(err))
+		return nil, err
+	}
+
+	metrics.RecordReservation("reserve", "success")
+	recordLowStock(productID, level, quantity)
+	cache.InvalidateDummyProducts([]uint{productID})
+	return reservation, nil
+}
+
+// ConfirmReservation turns a pending reservation into a sale, removing the
+// units from stock. A reservation that has already expired is released.
+func ConfirmReservation(ctx context.Context, id, userID string) (*models.StockReservation, error) {
+	return settleReservation(ctx, "confirm", id, userID, models.ReservationConfirmed)
+}
+
+// CancelReservation releases a pending reservation's units back into stock
+func CancelReservation(ctx context.Context, id, userID string) (*models.StockReservation, error) {
+	return settleReservation(ctx, "cancel", id, userID, models.ReservationCancelled)
+}
+
+// settleReservation moves a pending reservation to its final status. The
+// reservation row is locked first, then the product, matching the order used
+// by ReleaseExpiredReservations so the two cannot deadlock.
+func settleReservation(ctx context.Context, operation, id, userID, status string) (*models.StockReservation, error) {
+	var reservation models.StockReservation
+	expired := false
+
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", id, userID).
+			First(&reservation).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrReservationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if reservation.Status != models.ReservationPending {
+			return ErrReservationNotPending
+		}
+
+		// Confirming too late releases the units instead of selling them
+		if status == models.ReservationConfirmed && time.Now().After(reservation.ExpiresAt) {
+			status = models.ReservationExpired
+			expired = true
+		}
+
+		stock := gorm.Expr("stock_quantity")
+		if status == models.ReservationConfirmed {
+			stock = gorm.Expr("stock_quantity - ?", reservation.Quantity)
+		}
+		err = tx.Model(&models.DummyProduct{}).Unscoped().
+			Where("id = ?", reservation.DummyProductID).
+			Updates(map[string]interface{}{
+				"stock_quantity":    stock,
+				"reserved_quantity": gorm.Expr("reserved_quantity - ?", reservation.Quantity),
+				"version":           gorm.Expr("version + 1"),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		reservation.Status = status
+		return tx.Model(&reservation).Update("status", status).Error
+	})
+	if err == nil && expired {
+		err = ErrReservationExpired
+	}
+
+	if err != nil && !errors.Is(err, ErrReservationExpired) {
+		metrics.RecordReservation(operation, reservationResult(err))
+		return nil, err
+	}
+
+	metrics.RecordReservation(operation, reservationResult(err))
+	cache.InvalidateDummyProducts([]uint{reservation.DummyProductID})
+	return &reservation, err
+}
+
+// GetReservation loads a reservation owned by userID
+func GetReservation(ctx context.Context, id, userID string) (*models.StockReservation, error) {
+	var reservation models.StockReservation
+	err := database.DB.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&reservation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReservationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// SetStock sets the stock on hand of a product and optionally its low-stock
+// threshold. Stock can never be set below the units currently reserved.
+func SetStock(ctx context.Context, productID uint, quantity int, threshold *int) (*models.DummyProduct, error) {
+	updates := map[string]interface{}{
+		"stock_quantity": quantity,
+		"version":        gorm.Expr("version + 1"),
+	}
+	if threshold != nil {
+		updates["low_stock_threshold"] = *threshold
+	}
+
+	var product models.DummyProduct
+	var before int
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if quantity < product.ReservedQuantity {
+			return ErrStockBelowReserved
+		}
+		before = product.StockQuantity - product.ReservedQuantity
+		if err := tx.Model(&product).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.First(&product, productID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	available := product.StockQuantity - product.ReservedQuantity
+	recordLowStock(product.ID, stockLevel{Available: available, LowStockThreshold: product.LowStockThreshold}, before-available)
+	cache.InvalidateDummyProducts([]uint{product.ID})
+	return &product, nil
+}
+
+// ReleaseExpiredReservations marks pending reservations past their expiry as
+// expired and returns their units to the available stock. Rows locked by a
+// concurrent confirm or cancel are skipped and picked up on the next run.
+func ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	var released int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return released, err
+		}
+
+		var rows []struct {
+			DummyProductID uint
+			Quantity       int
+		}
+		err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Raw(`WITH expired AS (
+					UPDATE stock_reservations SET status = @expired, updated_at = now()
+					WHERE id IN (
+						SELECT id FROM stock_reservations
+						WHERE status = @pending AND expires_at < now()
+						ORDER BY expires_at
+						LIMIT @limit
+						FOR UPDATE SKIP LOCKED)
+					RETURNING dummy_product_id, quantity
+				)
+				SELECT dummy_product_id, sum(quantity) AS quantity FROM expired GROUP BY dummy_product_id`,
+				map[string]interface{}{
+					"expired": models.ReservationExpired,
+					"pending": models.ReservationPending,
+					"limit":   releaseBatchSize,
+				}).Scan(&rows).Error; err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				err := tx.Model(&models.DummyProduct{}).Unscoped().
+					Where("id = ?", row.DummyProductID).
+					Updates(map[string]interface{}{
+						"reserved_quantity": gorm.Expr("reserved_quantity - ?", row.Quantity),
+						"version":           gorm.Expr("version + 1"),
+					}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Error().Err(err).Int64("released", released).Msg("Failed to release expired reservations")
+			return released, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		ids := make([]uint, len(rows))
+		for i, row := range rows {
+			ids[i] = row.DummyProductID
+			released += int64(row.Quantity)
+		}
+		cache.InvalidateDummyProducts(ids)
+		metrics.InventoryReservations.WithLabelValues("expire", "success").Add(float64(len(rows)))
+	}
+
+	if released > 0 {
+		logger.Info().Int64("units", released).Msg("Released expired stock reservations")
+	}
+
+	return released, nil
+}
+
+// UpdateLowStockGauge refreshes the number of products at or below their low-stock threshold
+func UpdateLowStockGauge(ctx context.Context) error {
+	var count int64
+	err := database.DB.WithContext(ctx).Model(&models.DummyProduct{}).
+		Where("stock_quantity - reserved_quantity <= low_stock_threshold").
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	metrics.InventoryLowStockProducts.Set(float64(count))
+	return nil
+}
+
+// reservationResult maps an error to a metrics label
+func reservationResult(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrInsufficientStock):
+		return "insufficient_stock"
+	case errors.Is(err, ErrProductNotFound), errors.Is(err, ErrReservationNotFound):
+		return "not_found"
+	case errors.Is(err, ErrReservationNotPending):
+		return "not_pending"
+	case errors.Is(err, ErrReservationExpired):
+		return "expired"
+	case strings.Contains(err.Error(), "chk_dummy_products_stock"):
+		return "constraint_violation"
+	default:
+		return "error"
+	}
+}
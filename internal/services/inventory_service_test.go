@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"goapi-starter/internal/cache"
+	"goapi-starter/internal/database"
+	"goapi-starter/internal/models"
+	"goapi-starter/internal/money"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupInventoryTest connects to the database named by TEST_DATABASE_DSN and
+// creates a product with the given stock. The cache is kept in memory, so no
+// Redis is required.
+func setupInventoryTest(t *testing.T, stock int) models.DummyProduct {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set, skipping inventory integration test")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	database.DB = db
+	if err := database.RunMigrations(); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	if cache.Backend == nil {
+		cache.Backend = cache.NewMemoryStore()
+	}
+
+	product := models.DummyProduct{Name: "Inventory test", Price: money.MustParse("1.00"), Currency: "USD", StockQuantity: stock}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("Failed to create product: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Delete(&models.DummyProduct{}, product.ID)
+	})
+
+	return product
+}
+
+func TestReserveStockNeverOversells(t *testing.T) {
+	const stock, attempts = 25, 100
+	product := setupInventoryTest(t, stock)
+
+	var (
+		wg           sync.WaitGroup
+		mu           sync.Mutex
+		reserved     int
+		insufficient int
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ReserveStock(context.Background(), product.ID, uuid.New().String(), 1, time.Minute)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				reserved++
+			case errors.Is(err, ErrInsufficientStock):
+				insufficient++
+			default:
+				t.Errorf("Unexpected reservation error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != stock {
+		t.Errorf("Expected %d successful reservations, got %d", stock, reserved)
+	}
+	if insufficient != attempts-stock {
+		t.Errorf("Expected %d insufficient stock errors, got %d", attempts-stock, insufficient)
+	}
+
+	var stored models.DummyProduct
+	if err := database.DB.First(&stored, product.ID).Error; err != nil {
+		t.Fatalf("Failed to reload product: %v", err)
+	}
+	if stored.ReservedQuantity != stock {
+		t.Errorf("Expected reserved quantity %d, got %d", stock, stored.ReservedQuantity)
+	}
+}
+
+func TestReservationLifecycle(t *testing.T) {
+	product := setupInventoryTest(t, 10)
+	ctx := context.Background()
+	userID := uuid.New().String()
+
+	confirmed, err := ReserveStock(ctx, product.ID, userID, 4, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to reserve stock: %v", err)
+	}
+	if _, err := ConfirmReservation(ctx, confirmed.ID, userID); err != nil {
+		t.Fatalf("Failed to confirm reservation: %v", err)
+	}
+	if _, err := CancelReservation(ctx, confirmed.ID, userID); !errors.Is(err, ErrReservationNotPending) {
+		t.Errorf("Expected ErrReservationNotPending cancelling a confirmed reservation, got %v", err)
+	}
+
+	cancelled, err := ReserveStock(ctx, product.ID, userID, 3, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to reserve stock: %v", err)
+	}
+	if _, err := CancelReservation(ctx, cancelled.ID, uuid.New().String()); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("Expected ErrReservationNotFound for another user's reservation, got %v", err)
+	}
+	if _, err := CancelReservation(ctx, cancelled.ID, userID); err != nil {
+		t.Fatalf("Failed to cancel reservation: %v", err)
+	}
+
+	expired, err := ReserveStock(ctx, product.ID, userID, 2, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to reserve stock: %v", err)
+	}
+	database.DB.Model(&models.StockReservation{}).Where("id = ?", expired.ID).
+		Update("expires_at", time.Now().Add(-time.Second))
+	if _, err := ReleaseExpiredReservations(ctx); err != nil {
+		t.Fatalf("Failed to release expired reservations: %v", err)
+	}
+	if _, err := ConfirmReservation(ctx, expired.ID, userID); !errors.Is(err, ErrReservationNotPending) {
+		t.Errorf("Expected ErrReservationNotPending confirming a released reservation, got %v", err)
+	}
+
+	var stored models.DummyProduct
+	if err := database.DB.First(&stored, product.ID).Error; err != nil {
+		t.Fatalf("Failed to reload product: %v", err)
+	}
+	if stored.StockQuantity != 6 || stored.ReservedQuantity != 0 {
+		t.Errorf("Expected stock 6 with nothing reserved, got stock %d reserved %d", stored.StockQuantity, stored.ReservedQuantity)
+	}
+}
@@ -164,10 +164,11 @@ func ValidateRefreshToken(tokenString string) (*models.User, error) {
 	// Check if token is blacklisted
 	blacklisted, err := cache.IsRefreshTokenBlacklisted(tokenString)
 	if err != nil {
+		// Only returned when CACHE_BLACKLIST_FAILURE_POLICY is closed
 		logger.Warn().
 			Err(err).
 			Msg("Error checking refresh token blacklist")
-		// Continue even if blacklist check fails
+		return nil, errors.New("unable to verify refresh token")
 	} else if blacklisted {
 		logger.Warn().
 			Msg("Refresh token is blacklisted")
@@ -230,47 +231,23 @@ func ValidateRefreshToken(tokenString string) (*models.User, error) {
 
 // Helper function to get user by ID (from cache or database)
 func getUserForToken(userID string) (*models.User, error) {
-	// Try to get user from cache first
-	cachedUser, found, err := cache.GetCachedUser(userID)
+	user, err := cache.GetOrLoadUser(userID, func() (models.User, error) {
+		var user models.User
+		err := database.DB.First(&user, "id = ?", userID).Error
+		return user, err
+	})
 	if err != nil {
-		logger.Warn().
-			Err(err).
-			Str("user_id", userID).
-			Msg("Error retrieving user from cache")
-		// Continue with database lookup
-	}
-
-	if found && cachedUser != nil {
-		logger.Info().
-			Str("user_id", cachedUser.ID).
-			Str("username", cachedUser.Username).
-			Msg("User retrieved from cache for refresh token")
-		return cachedUser, nil
-	}
-
-	// User not in cache, get from database
-	var user models.User
-	if result := database.DB.First(&user, "id = ?", userID); result.Error != nil {
 		logger.Error().
-			Err(result.Error).
+			Err(err).
 			Str("user_id", userID).
 			Msg("User not found for refresh token")
 		return nil, errors.New("user not found")
 	}
 
-	// Cache the user for future requests
-	if err := cache.CacheUser(user); err != nil {
-		logger.Warn().
-			Err(err).
-			Str("user_id", user.ID).
-			Msg("Failed to cache user data during token validation")
-		// Continue even if caching fails
-	}
-
 	logger.Info().
 		Str("user_id", user.ID).
 		Str("username", user.Username).
 		Msg("Refresh token validated successfully")
 
-	return &user, nil
+	return user, nil
 }
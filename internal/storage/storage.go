@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"goapi-starter/internal/config"
+	"goapi-starter/internal/logger"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when an object does not exist
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey is returned for keys that could escape the storage root
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Object describes a stored blob
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Storage stores blobs by key. Keys are slash-separated paths such as
+// "products/1/images/<id>/original.jpg".
+type Storage interface {
+	// Put stores the content of r under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens an object for reading; the caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that allows downloading the object until ttl elapses
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Blobs is the storage used by the application
+var Blobs Storage
+
+// InitStorage creates the storage selected by the configuration
+func InitStorage() {
+	cfg := config.AppConfig.Storage
+
+	var err error
+	switch cfg.Driver {
+	case "local":
+		Blobs, err = NewLocal(cfg.LocalDir, cfg.PublicURL, cfg.SigningSecret)
+	case "s3":
+		Blobs, err = NewS3(cfg.S3)
+	default:
+		err = fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+	if err != nil {
+		logger.Fatal().Err(err).Str("driver", cfg.Driver).Msg("Failed to initialize storage")
+	}
+
+	logger.Info().Str("driver", cfg.Driver).Msg("Storage initialized")
+}
+
+// DeleteAll removes several objects, continuing past failures and returning
+// the first error
+func DeleteAll(ctx context.Context, s Storage, keys []string) error {
+	var first error
+	for _, key := range keys {
+		if err := s.Delete(ctx, key); err != nil {
+			logger.Warn().Err(err).Str("key", key).Msg("Failed to delete stored object")
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// validateKey rejects empty keys and keys with empty, "." or ".." segments
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ETagMatches reports whether the given If-Match or If-None-Match header value
+// matches etag. A "*" matches any current representation. Weak validators
+// (W/"...") only match when allowWeak is set, as If-Match requires strong comparison.
+func ETagMatches(header, etag string, allowWeak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !allowWeak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckNotModified sets the ETag response header and, when the request's
+// If-None-Match header matches it, writes a 304 Not Modified response.
+// It returns true when the response has been written.
+func CheckNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if ETagMatches(r.Header.Get("If-None-Match"), etag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// FieldSelection is a sparse fieldset requested with ?fields=id,name,price
+// and the related resources requested with ?expand=owner,category. Field
+// names are the JSON names of the model's columns, so handlers can push the
+// projection down into the SQL select.
+type FieldSelection struct {
+	Fields  []string
+	Expand  []string
+	columns map[string]string
+}
+
+// schemaCache holds parsed model schemas for ParseFieldSelection
+var schemaCache sync.Map
+
+// ParseFieldSelection validates the fields and expand query parameters
+// against the column-backed JSON fields of model and the allowed relations
+func ParseFieldSelection(values url.Values, model interface{}, relations ...string) (FieldSelection, error) {
+	columns, err := jsonColumns(model)
+	if err != nil {
+		return FieldSelection{}, err
+	}
+
+	s := FieldSelection{columns: columns}
+	for _, field := range splitList(values.Get("fields")) {
+		if _, ok := columns[field]; !ok {
+			return FieldSelection{}, fmt.Errorf("unknown field %q", field)
+		}
+		if !slices.Contains(s.Fields, field) {
+			s.Fields = append(s.Fields, field)
+		}
+	}
+	for _, relation := range splitList(values.Get("expand")) {
+		if !slices.Contains(relations, relation) {
+			return FieldSelection{}, fmt.Errorf("cannot expand %q", relation)
+		}
+		if !slices.Contains(s.Expand, relation) {
+			s.Expand = append(s.Expand, relation)
+		}
+	}
+	return s, nil
+}
+
+// IsZero reports whether neither fields nor expansions were requested
+func (s FieldSelection) IsZero() bool {
+	return len(s.Fields) == 0 && len(s.Expand) == 0
+}
+
+// Sparse reports whether only some fields were requested
+func (s FieldSelection) Sparse() bool {
+	return len(s.Fields) > 0
+}
+
+// Expands reports whether the relation was requested
+func (s FieldSelection) Expands(relation string) bool {
+	return slices.Contains(s.Expand, relation)
+}
+
+// Columns returns the columns backing the requested fields plus the required
+// columns, or nil when every field was requested
+func (s FieldSelection) Columns(required ...string) []string {
+	if !s.Sparse() {
+		return nil
+	}
+
+	columns := make([]string, 0, len(s.Fields)+len(required))
+	for _, field := range s.Fields {
+		columns = append(columns, s.columns[field])
+	}
+	for _, column := range required {
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// Key is a canonical form of the selection for cache keys and ETags
+func (s FieldSelection) Key() string {
+	fields := slices.Sorted(slices.Values(s.Fields))
+	expand := slices.Sorted(slices.Values(s.Expand))
+	return "fields=" + strings.Join(fields, ",") + "&expand=" + strings.Join(expand, ",")
+}
+
+// Project reduces an object, or each object of a slice, to the requested
+// fields, the expanded relations and any extra keys. v is returned unchanged
+// when every field was requested.
+func (s FieldSelection) Project(v interface{}, extra ...string) (interface{}, error) {
+	if !s.Sparse() {
+		return v, nil
+	}
+
+	keep := make(map[string]bool, len(s.Fields)+len(s.Expand)+len(extra))
+	for _, keys := range [][]string{s.Fields, s.Expand, extra} {
+		for _, key := range keys {
+			keep[key] = true
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			pruneKeys(item, keep)
+		}
+		return items, nil
+	}
+
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	pruneKeys(item, keep)
+	return item, nil
+}
+
+func pruneKeys(item map[string]json.RawMessage, keep map[string]bool) {
+	for key := range item {
+		if !keep[key] {
+			delete(item, key)
+		}
+	}
+}
+
+// jsonColumns maps the JSON names of a model's column fields to their columns
+func jsonColumns(model interface{}) (map[string]string, error) {
+	parsed, err := schema.Parse(model, &schemaCache, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]string)
+	for _, field := range parsed.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns[name] = field.DBName
+	}
+	return columns, nil
+}
+
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
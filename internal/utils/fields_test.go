@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/json"
+	"goapi-starter/internal/models"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldSelection(t *testing.T) {
+	values := url.Values{"fields": {"id,name, price,name"}, "expand": {"category"}}
+	selection, err := ParseFieldSelection(values, &models.DummyProduct{}, "owner", "category")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"id", "name", "price"}; !reflect.DeepEqual(selection.Fields, want) {
+		t.Errorf("Fields = %v, want %v", selection.Fields, want)
+	}
+	if want := []string{"id", "name", "price", "version"}; !reflect.DeepEqual(selection.Columns("version", "id"), want) {
+		t.Errorf("Columns = %v, want %v", selection.Columns("version", "id"), want)
+	}
+
+	for _, bad := range []url.Values{
+		{"fields": {"password"}},
+		{"fields": {"display_price"}},
+		{"expand": {"tags"}},
+	} {
+		if _, err := ParseFieldSelection(bad, &models.DummyProduct{}, "owner", "category"); err == nil {
+			t.Errorf("expected %v to be rejected", bad)
+		}
+	}
+
+	if _, err := ParseFieldSelection(url.Values{"fields": {"password"}}, &models.User{}); err == nil {
+		t.Error("expected hidden user fields to be rejected")
+	}
+}
+
+func TestFieldSelectionProject(t *testing.T) {
+	selection, err := ParseFieldSelection(url.Values{"fields": {"id,name"}}, &models.DummyProduct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projected, err := selection.Project([]models.DummyProduct{{ID: 1, Name: "Lamp", Description: "Bright"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(projected)
+	if string(data) != `[{"id":1,"name":"Lamp"}]` {
+		t.Errorf("Project = %s", data)
+	}
+
+	full := models.DummyProduct{ID: 1}
+	if unchanged, _ := (FieldSelection{}).Project(full); !reflect.DeepEqual(unchanged, full) {
+		t.Error("an empty selection should return the value unchanged")
+	}
+}
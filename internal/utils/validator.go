@@ -1,11 +1,37 @@
 package utils
 
 import (
+	"goapi-starter/internal/money"
+	"reflect"
+
 	"github.com/go-playground/validator/v10"
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+// Validator is implemented by request types with checks that span several fields
+type Validator interface {
+	Validate() error
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// Decimals are validated by their sign, so "required,gt=0" means positive
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if d, ok := field.Interface().(money.Decimal); ok {
+			return d.Sign()
+		}
+		return nil
+	}, money.Decimal{})
+	return v
+}
 
 func ValidateStruct(s interface{}) error {
-	return validate.Struct(s)
+	if err := validate.Struct(s); err != nil {
+		return err
+	}
+	if v, ok := s.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
 }